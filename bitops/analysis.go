@@ -0,0 +1,95 @@
+package bitops
+
+import "math"
+
+// PercentileBits returns the number of bits required to store at least the
+// fraction p of the values in src.  p should be between 0 and 1; a p of 0.99
+// returns the width needed by 99% of the values, ignoring the largest 1%.
+func PercentileBits(src []uint64, p float64) int {
+	if len(src) == 0 {
+		return 0
+	}
+
+	if p < 0 {
+		p = 0
+	} else if p > 1 {
+		p = 1
+	}
+
+	// Count the values requiring each bit width
+	var widths [65]int
+	for _, v := range src {
+		widths[msb64(v)+1]++
+	}
+
+	need := int(math.Ceil(p * float64(len(src))))
+	var total int
+	for bits, n := range widths {
+		total += n
+		if total >= need {
+			return bits
+		}
+	}
+	return 64
+}
+
+// maxSamples is the number of values examined by CompressibilityScore
+const maxSamples = 4096
+
+// CompressibilityScore returns a heuristic between 0 and 1 describing how well
+// src is likely to compress, where 1 is highly compressible.  Columns where
+// every value is equal score 1, otherwise the score falls as the average bit
+// width of the values grows.  Large inputs are sampled at a fixed stride so the
+// cost is bounded regardless of the length of src.
+func CompressibilityScore(src []uint64) float64 {
+	if len(src) == 0 {
+		return 1
+	}
+
+	stride := 1
+	if len(src) > maxSamples {
+		stride = len(src) / maxSamples
+	}
+
+	var (
+		bits, n int
+		equal   = true
+	)
+	for i := 0; i < len(src); i += stride {
+		v := src[i]
+		if v != src[0] {
+			equal = false
+		}
+		bits += msb64(v) + 1
+		n++
+	}
+
+	if equal {
+		return 1
+	}
+	return 1 - float64(bits)/float64(n*64)
+}
+
+// ClassifyWidths returns the number of values in src requiring each bit width
+// from 0 to 60.  Values wider than 60 bits cannot be packed by simple8b and are
+// not counted.
+func ClassifyWidths(src []uint64) [61]int {
+	var widths [61]int
+	for _, v := range src {
+		if bits := msb64(v) + 1; bits <= 60 {
+			widths[bits]++
+		}
+	}
+	return widths
+}
+
+// MinEncodedBits returns the sum of the bit widths of the values in src.  This
+// is the floor for packing encodings such as simple8b, which store each value
+// in at least as many bits as it needs.  Zero values need no bits.
+func MinEncodedBits(src []uint64) float64 {
+	var bits int
+	for _, v := range src {
+		bits += msb64(v) + 1
+	}
+	return float64(bits)
+}
@@ -0,0 +1,134 @@
+package bitops_test
+
+import (
+	"math/bits"
+	"math/rand"
+	"testing"
+
+	"github.com/jwilder/encoding/bitops"
+)
+
+func TestPercentileBits(t *testing.T) {
+	src := make([]uint64, 1000)
+	for i := 0; i < len(src); i++ {
+		src[i] = uint64(i % 16)
+	}
+
+	// A few large outliers
+	for i := 0; i < 10; i++ {
+		src[i*100] = 1 << 39
+	}
+
+	tests := []struct {
+		p   float64
+		exp int
+	}{
+		{0.5, 4},
+		{0.99, 4},
+		{0.995, 40},
+		{1, 40},
+	}
+
+	for _, test := range tests {
+		if got := bitops.PercentileBits(src, test.p); got != test.exp {
+			t.Fatalf("PercentileBits(%v) mismatch: exp %v, got %v", test.p, test.exp, got)
+		}
+	}
+}
+
+func TestPercentileBits_Empty(t *testing.T) {
+	if exp, got := 0, bitops.PercentileBits(nil, 0.99); got != exp {
+		t.Fatalf("PercentileBits mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestCompressibilityScore_AllEqual(t *testing.T) {
+	src := make([]uint64, 100000)
+	for i := 0; i < len(src); i++ {
+		src[i] = 1 << 40
+	}
+
+	if exp, got := 1.0, bitops.CompressibilityScore(src); got != exp {
+		t.Fatalf("CompressibilityScore mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestCompressibilityScore_RandomSmall(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	src := make([]uint64, 100000)
+	for i := 0; i < len(src); i++ {
+		src[i] = uint64(rnd.Intn(16))
+	}
+
+	if got := bitops.CompressibilityScore(src); got < 0.9 || got >= 1 {
+		t.Fatalf("CompressibilityScore out of range: got %v", got)
+	}
+}
+
+func TestCompressibilityScore_RandomFull(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	src := make([]uint64, 100000)
+	for i := 0; i < len(src); i++ {
+		src[i] = rnd.Uint64() | 1<<63
+	}
+
+	if got := bitops.CompressibilityScore(src); got > 0.01 {
+		t.Fatalf("CompressibilityScore out of range: got %v", got)
+	}
+}
+
+func TestClassifyWidths(t *testing.T) {
+	var src []uint64
+	for i := 0; i < 5; i++ {
+		src = append(src, 0)
+	}
+	for i := 0; i < 10; i++ {
+		src = append(src, 1)
+	}
+	for i := 0; i < 20; i++ {
+		src = append(src, uint64(8+i%8))
+	}
+	src = append(src, 1<<59, 1<<62)
+
+	widths := bitops.ClassifyWidths(src)
+
+	var exp [61]int
+	exp[0] = 5
+	exp[1] = 10
+	exp[4] = 20
+	exp[60] = 1
+
+	if widths != exp {
+		t.Fatalf("ClassifyWidths mismatch: exp %v, got %v", exp, widths)
+	}
+}
+
+func TestMinEncodedBits_AllEqual(t *testing.T) {
+	src := make([]uint64, 10000)
+	if got := bitops.MinEncodedBits(src); got != 0 {
+		t.Fatalf("MinEncodedBits mismatch: exp %v, got %v", 0, got)
+	}
+
+	for i := 0; i < len(src); i++ {
+		src[i] = 12345
+	}
+
+	// 12345 needs 14 bits
+	if exp, got := float64(14*len(src)), bitops.MinEncodedBits(src); got != exp {
+		t.Fatalf("MinEncodedBits mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestMinEncodedBits_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	src := make([]uint64, 100000)
+	var exp float64
+	for i := 0; i < len(src); i++ {
+		src[i] = uint64(rnd.Intn(256))
+		exp += float64(bits.Len64(src[i]))
+	}
+
+	if got := bitops.MinEncodedBits(src); got != exp {
+		t.Fatalf("MinEncodedBits mismatch: exp %v, got %v", exp, got)
+	}
+}
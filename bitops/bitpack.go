@@ -0,0 +1,108 @@
+package bitops
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// BitPack packs the low bits bits of each value in src into a byte slice.
+// Values are written least significant bit first starting at the low bit of the
+// first byte.  The result holds len(src)*bits bits rounded up to a whole byte.
+func BitPack(src []uint64, bits int) []byte {
+	dst := make([]byte, (len(src)*bits+7)/8)
+	pos := 0
+	for _, v := range src {
+		for i := 0; i < bits; {
+			off := uint((pos + i) % 8)
+			take := 8 - int(off)
+			if take > bits-i {
+				take = bits - i
+			}
+			dst[(pos+i)/8] |= byte((v>>uint(i))&(1<<uint(take)-1)) << off
+			i += take
+		}
+		pos += bits
+	}
+	return dst
+}
+
+// BitUnpack reverses BitPack, filling dst with the bits wide values stored in
+// src.  src must hold at least len(dst)*bits bits.
+func BitUnpack(dst []uint64, src []byte, bits int) {
+	pos := 0
+	for j := range dst {
+		var v uint64
+		for i := 0; i < bits; {
+			off := uint((pos + i) % 8)
+			take := 8 - int(off)
+			if take > bits-i {
+				take = bits - i
+			}
+			v |= (uint64(src[(pos+i)/8]>>off) & (1<<uint(take) - 1)) << uint(i)
+			i += take
+		}
+		dst[j] = v
+		pos += bits
+	}
+}
+
+// maxZeroWidthValues is the largest number of values PackMinWidth stores at a
+// bit width of 0.  Such values take no bytes after the header, so the count is
+// all UnpackMinWidth has to size its output by.
+const maxZeroWidthValues = 1 << 28
+
+// PackMinWidth packs src at the bit width of its largest value.  The result is
+// prefixed with the number of values as a uvarint and the width as one byte, so
+// it can be reversed by UnpackMinWidth without any other information.  More
+// than maxZeroWidthValues zeros cannot be packed.
+func PackMinWidth(src []uint64) ([]byte, error) {
+	var max uint64
+	for _, v := range src {
+		if v > max {
+			max = v
+		}
+	}
+	bits := msb64(max) + 1
+
+	if bits == 0 && len(src) > maxZeroWidthValues {
+		return nil, fmt.Errorf("count too large: %v > %v", len(src), maxZeroWidthValues)
+	}
+
+	var hdr [binary.MaxVarintLen64 + 1]byte
+	n := binary.PutUvarint(hdr[:], uint64(len(src)))
+	hdr[n] = byte(bits)
+	return append(hdr[:n+1], BitPack(src, bits)...), nil
+}
+
+// UnpackMinWidth returns the values packed by PackMinWidth.
+func UnpackMinWidth(b []byte) ([]uint64, error) {
+	count, n := binary.Uvarint(b)
+	if n <= 0 {
+		return nil, fmt.Errorf("unable to decode value count")
+	}
+	b = b[n:]
+
+	if len(b) < 1 {
+		return nil, fmt.Errorf("missing bit width")
+	}
+	bits := int(b[0])
+	b = b[1:]
+
+	if bits > 64 {
+		return nil, fmt.Errorf("invalid bit width: %v", bits)
+	}
+
+	if bits > 0 && count > uint64(len(b))*8/uint64(bits) {
+		return nil, fmt.Errorf("not enough bytes for %v values: %v", count, len(b))
+	}
+
+	// Zero width values take no bytes, so only the limit PackMinWidth applies
+	// stops a corrupt count from sizing a huge allocation.
+	if bits == 0 && count > maxZeroWidthValues {
+		return nil, fmt.Errorf("count too large: %v > %v", count, maxZeroWidthValues)
+	}
+
+	dst := make([]uint64, count)
+	BitUnpack(dst, b, bits)
+	return dst, nil
+}
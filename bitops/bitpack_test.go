@@ -0,0 +1,94 @@
+package bitops_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/jwilder/encoding/bitops"
+)
+
+func TestBitPack(t *testing.T) {
+	for _, bits := range []int{0, 1, 3, 8, 13, 32, 60, 64} {
+		in := make([]uint64, 100)
+		for i := 0; i < len(in); i++ {
+			if bits > 0 {
+				in[i] = (uint64(i) * 0x9E3779B97F4A7C15) >> uint(64-bits)
+			}
+		}
+
+		packed := bitops.BitPack(in, bits)
+		if exp, got := (len(in)*bits+7)/8, len(packed); got != exp {
+			t.Fatalf("BitPack(%d) len mismatch: exp %v, got %v", bits, exp, got)
+		}
+
+		out := make([]uint64, len(in))
+		bitops.BitUnpack(out, packed, bits)
+		for i := 0; i < len(in); i++ {
+			if out[i] != in[i] {
+				t.Fatalf("BitUnpack(%d)[%d] != %v, got %v", bits, i, in[i], out[i])
+			}
+		}
+	}
+}
+
+func TestPackMinWidth(t *testing.T) {
+	for _, bits := range []int{0, 1, 7, 17, 33, 60, 64} {
+		in := make([]uint64, 77)
+		for i := 0; i < len(in); i++ {
+			if bits > 0 {
+				in[i] = (uint64(i) * 0x9E3779B97F4A7C15) >> uint(64-bits)
+			}
+		}
+		if bits > 0 {
+			in[len(in)/2] = 1<<uint(bits-1) | 1
+		}
+
+		b, err := bitops.PackMinWidth(in)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if exp, got := 2+(len(in)*bits+7)/8, len(b); got != exp {
+			t.Fatalf("PackMinWidth(%d) len mismatch: exp %v, got %v", bits, exp, got)
+		}
+
+		out, err := bitops.UnpackMinWidth(b)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if exp, got := len(in), len(out); got != exp {
+			t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+		}
+
+		for i := 0; i < len(in); i++ {
+			if out[i] != in[i] {
+				t.Fatalf("UnpackMinWidth(%d)[%d] != %v, got %v", bits, i, in[i], out[i])
+			}
+		}
+	}
+}
+
+func TestUnpackMinWidth_Truncated(t *testing.T) {
+	b, err := bitops.PackMinWidth([]uint64{1, 2, 3, 4, 5, 6, 7, 8})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := bitops.UnpackMinWidth(b[:len(b)-1]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestUnpackMinWidth_ZeroWidthCount(t *testing.T) {
+	for _, count := range []uint64{1<<28 + 1, math.MaxUint64} {
+		b := make([]byte, binary.MaxVarintLen64+1)
+		n := binary.PutUvarint(b, count)
+		b[n] = 0
+
+		if _, err := bitops.UnpackMinWidth(b[:n+1]); err == nil {
+			t.Fatalf("Expected error for count %v, got nil", count)
+		}
+	}
+}
@@ -63,6 +63,12 @@ func msb64(n uint64) int {
 	return int(r)
 }
 
+// BitLen returns the number of bits required to store x, which is 0 for a value
+// of 0.
+func BitLen(x uint64) int {
+	return msb64(x) + 1
+}
+
 func ZigZagEncode64(x int64) uint64 {
 	return uint64(uint64(x<<1) ^ uint64((int64(x) >> 63)))
 }
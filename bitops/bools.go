@@ -0,0 +1,43 @@
+package bitops
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// EncodeBools packs src using one bit per value, prefixed with the number of
+// values as a uvarint.
+func EncodeBools(src []bool) ([]byte, error) {
+	values := make([]uint64, len(src))
+	for i, v := range src {
+		if v {
+			values[i] = 1
+		}
+	}
+
+	var hdr [binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(hdr[:], uint64(len(src)))
+	return append(hdr[:n], BitPack(values, 1)...), nil
+}
+
+// DecodeBools returns the values packed by EncodeBools.
+func DecodeBools(b []byte) ([]bool, error) {
+	count, n := binary.Uvarint(b)
+	if n <= 0 {
+		return nil, fmt.Errorf("unable to decode value count")
+	}
+	b = b[n:]
+
+	if uint64(len(b))*8 < count {
+		return nil, fmt.Errorf("not enough bytes for %v values: %v", count, len(b))
+	}
+
+	values := make([]uint64, count)
+	BitUnpack(values, b, 1)
+
+	dst := make([]bool, count)
+	for i, v := range values {
+		dst[i] = v == 1
+	}
+	return dst, nil
+}
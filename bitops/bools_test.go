@@ -0,0 +1,49 @@
+package bitops_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/bitops"
+)
+
+func TestEncodeBools(t *testing.T) {
+	in := make([]bool, 1001)
+	for i := 0; i < len(in); i++ {
+		in[i] = i%3 == 0 || i%7 == 0
+	}
+
+	b, err := bitops.EncodeBools(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 2+(len(in)+7)/8, len(b); got != exp {
+		t.Fatalf("Encoded len mismatch: exp %v, got %v", exp, got)
+	}
+
+	out, err := bitops.DecodeBools(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), len(out); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if out[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], out[i])
+		}
+	}
+}
+
+func TestDecodeBools_Truncated(t *testing.T) {
+	b, err := bitops.EncodeBools(make([]bool, 100))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := bitops.DecodeBools(b[:len(b)-1]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
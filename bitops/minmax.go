@@ -0,0 +1,36 @@
+package bitops
+
+// MinMax returns the smallest and largest values in src along with the index of
+// their first occurrence.  If src is empty, both indexes are -1.
+func MinMax(src []uint64) (min, max uint64, minIdx, maxIdx int) {
+	if len(src) == 0 {
+		return 0, 0, -1, -1
+	}
+
+	min, max = src[0], src[0]
+	for i, v := range src[1:] {
+		if v < min {
+			min, minIdx = v, i+1
+		} else if v > max {
+			max, maxIdx = v, i+1
+		}
+	}
+	return min, max, minIdx, maxIdx
+}
+
+// MinMaxInt64 is like MinMax but for signed values.
+func MinMaxInt64(src []int64) (min, max int64, minIdx, maxIdx int) {
+	if len(src) == 0 {
+		return 0, 0, -1, -1
+	}
+
+	min, max = src[0], src[0]
+	for i, v := range src[1:] {
+		if v < min {
+			min, minIdx = v, i+1
+		} else if v > max {
+			max, maxIdx = v, i+1
+		}
+	}
+	return min, max, minIdx, maxIdx
+}
@@ -0,0 +1,48 @@
+package bitops_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/bitops"
+)
+
+func TestMinMax(t *testing.T) {
+	tests := []struct {
+		src            []uint64
+		min, max       uint64
+		minIdx, maxIdx int
+	}{
+		{nil, 0, 0, -1, -1},
+		{[]uint64{7}, 7, 7, 0, 0},
+		{[]uint64{5, 3, 9, 3, 9, 1, 4}, 1, 9, 5, 2},
+		{[]uint64{1 << 63, 0}, 0, 1 << 63, 1, 0},
+	}
+
+	for _, test := range tests {
+		min, max, minIdx, maxIdx := bitops.MinMax(test.src)
+		if min != test.min || max != test.max || minIdx != test.minIdx || maxIdx != test.maxIdx {
+			t.Fatalf("MinMax(%v) mismatch: exp %v, %v, %v, %v, got %v, %v, %v, %v",
+				test.src, test.min, test.max, test.minIdx, test.maxIdx, min, max, minIdx, maxIdx)
+		}
+	}
+}
+
+func TestMinMaxInt64(t *testing.T) {
+	tests := []struct {
+		src            []int64
+		min, max       int64
+		minIdx, maxIdx int
+	}{
+		{nil, 0, 0, -1, -1},
+		{[]int64{-7}, -7, -7, 0, 0},
+		{[]int64{5, -3, 9, -3, 9, -10, 4}, -10, 9, 5, 2},
+	}
+
+	for _, test := range tests {
+		min, max, minIdx, maxIdx := bitops.MinMaxInt64(test.src)
+		if min != test.min || max != test.max || minIdx != test.minIdx || maxIdx != test.maxIdx {
+			t.Fatalf("MinMaxInt64(%v) mismatch: exp %v, %v, %v, %v, got %v, %v, %v, %v",
+				test.src, test.min, test.max, test.minIdx, test.maxIdx, min, max, minIdx, maxIdx)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package column implements a streaming codec for int64 columns.  Values are
+// delta encoded against the previous value, zigzag encoded so small negative
+// deltas stay small, and packed with simple8b.  Slowly changing series such as
+// timestamps and counters compress to a few bits per value.
+//
+// The framed output holds the number of values as a uvarint, the first value
+// as a varint and the simple8b encoded zigzag deltas of the remaining values.
+package column
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/jwilder/encoding/bitops"
+	"github.com/jwilder/encoding/simple8b"
+)
+
+// Writer encodes a stream of int64 values into a framed byte slice.
+type Writer struct {
+	enc *simple8b.Encoder
+
+	// first value written and the most recent value written
+	first int64
+	prev  int64
+
+	// number of values written
+	n int
+}
+
+// NewWriter returns a Writer ready to accept values.
+func NewWriter() *Writer {
+	return &Writer{
+		enc: simple8b.NewEncoder(),
+	}
+}
+
+// Write appends v to the column.  An error is returned if the delta from the
+// previous value is too large to encode.
+func (w *Writer) Write(v int64) error {
+	if w.n > 0 {
+		if err := w.enc.Write(bitops.ZigZagEncode64(v - w.prev)); err != nil {
+			return err
+		}
+	} else {
+		w.first = v
+	}
+	w.prev = v
+	w.n += 1
+	return nil
+}
+
+// Bytes flushes any buffered values and returns the framed column.
+func (w *Writer) Bytes() ([]byte, error) {
+	body, err := w.enc.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 2*binary.MaxVarintLen64, 2*binary.MaxVarintLen64+len(body))
+	i := binary.PutUvarint(b, uint64(w.n))
+	i += binary.PutVarint(b[i:], w.first)
+	return append(b[:i], body...), nil
+}
+
+// Reset clears the writer so it can encode a new column.
+func (w *Writer) Reset() {
+	w.enc.Reset()
+	w.first = 0
+	w.prev = 0
+	w.n = 0
+}
+
+// Reader decodes the values of a column produced by Writer.
+type Reader struct {
+	dec *simple8b.Decoder
+
+	// total number of values in the column and number read so far
+	n int
+	i int
+
+	// first value in the column and the current value
+	first int64
+	v     int64
+}
+
+// NewReader returns a Reader over the framed column b.
+func NewReader(b []byte) (*Reader, error) {
+	n, i := binary.Uvarint(b)
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid column count")
+	}
+	b = b[i:]
+
+	first, i := binary.Varint(b)
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid column first value")
+	}
+	b = b[i:]
+
+	return &Reader{
+		dec:   simple8b.NewDecoder(b),
+		n:     int(n),
+		first: first,
+	}, nil
+}
+
+// Next advances to the next value and returns false when the column is
+// exhausted.
+func (r *Reader) Next() bool {
+	if r.i >= r.n {
+		return false
+	}
+
+	if r.i == 0 {
+		r.v = r.first
+	} else {
+		if !r.dec.Next() {
+			return false
+		}
+		r.v += bitops.ZigZagDecode64(r.dec.Read())
+	}
+	r.i += 1
+	return true
+}
+
+// Read returns the current value.
+func (r *Reader) Read() int64 {
+	return r.v
+}
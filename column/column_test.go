@@ -0,0 +1,107 @@
+package column_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jwilder/encoding/column"
+)
+
+func TestWriter_RoundTrip(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	// Millisecond timestamps 10s apart with up to +/-50ms of jitter
+	in := make([]int64, 10000)
+	start := int64(1500000000000)
+	for i := 0; i < len(in); i++ {
+		in[i] = start + int64(i)*10000 + rng.Int63n(100) - 50
+	}
+
+	w := column.NewWriter()
+	for _, v := range in {
+		if err := w.Write(v); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+
+	b, err := w.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if raw := len(in) * 8; len(b) >= raw/3 {
+		t.Fatalf("Expected at least 3x compression: raw %v, got %v", raw, len(b))
+	}
+
+	r, err := column.NewReader(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	i := 0
+	for r.Next() {
+		if r.Read() != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], r.Read())
+		}
+		i += 1
+	}
+
+	if exp, got := len(in), i; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestWriter_Empty(t *testing.T) {
+	w := column.NewWriter()
+	b, err := w.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	r, err := column.NewReader(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if r.Next() {
+		t.Fatalf("Expected no values, got %v", r.Read())
+	}
+}
+
+func TestWriter_Reset(t *testing.T) {
+	w := column.NewWriter()
+	w.Write(100)
+	w.Write(-5)
+	w.Reset()
+	w.Write(-7)
+	w.Write(-9)
+
+	b, err := w.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	r, err := column.NewReader(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	exp := []int64{-7, -9}
+	i := 0
+	for r.Next() {
+		if r.Read() != exp[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, exp[i], r.Read())
+		}
+		i += 1
+	}
+
+	if i != len(exp) {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", len(exp), i)
+	}
+}
+
+func TestNewReader_Invalid(t *testing.T) {
+	if _, err := column.NewReader(nil); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
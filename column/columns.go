@@ -0,0 +1,155 @@
+package column
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/jwilder/encoding/bitops"
+	"github.com/jwilder/encoding/simple8b"
+)
+
+// EncodeColumns packs each column of cols as its own simple8b stream so values
+// from the same field are stored together.  The streams are preceded by a
+// directory holding the number of columns and the byte length of each stream
+// as uvarints.
+func EncodeColumns(cols [][]uint64) ([]byte, error) {
+	streams := make([][]byte, len(cols))
+	dir := make([]byte, (len(cols)+1)*binary.MaxVarintLen64)
+	n := binary.PutUvarint(dir, uint64(len(cols)))
+
+	size := 0
+	for i, col := range cols {
+		enc := simple8b.NewEncoder()
+		for _, v := range col {
+			if err := enc.Write(v); err != nil {
+				return nil, err
+			}
+		}
+
+		b, err := enc.Bytes()
+		if err != nil {
+			return nil, err
+		}
+		streams[i] = b
+		n += binary.PutUvarint(dir[n:], uint64(len(b)))
+		size += len(b)
+	}
+
+	b := make([]byte, 0, n+size)
+	b = append(b, dir[:n]...)
+	for _, s := range streams {
+		b = append(b, s...)
+	}
+	return b, nil
+}
+
+// DecodeColumns returns the columns encoded by EncodeColumns.
+func DecodeColumns(b []byte) ([][]uint64, error) {
+	n, i := binary.Uvarint(b)
+	if i <= 0 {
+		return nil, fmt.Errorf("invalid column count")
+	}
+	b = b[i:]
+
+	// Each column needs at least one directory byte
+	if n > uint64(len(b)) {
+		return nil, fmt.Errorf("invalid column count: %v", n)
+	}
+
+	sizes := make([]uint64, n)
+	for j := range sizes {
+		size, i := binary.Uvarint(b)
+		if i <= 0 {
+			return nil, fmt.Errorf("invalid length for column %v", j)
+		}
+		sizes[j] = size
+		b = b[i:]
+	}
+
+	cols := make([][]uint64, n)
+	for j, size := range sizes {
+		if size > uint64(len(b)) {
+			return nil, fmt.Errorf("column %v truncated: need %v bytes, got %v", j, size, len(b))
+		}
+
+		count, err := simple8b.CountBytes(b[:size])
+		if err != nil {
+			return nil, err
+		}
+
+		col := make([]uint64, 0, count)
+		dec := simple8b.NewDecoder(b[:size])
+		for dec.Next() {
+			col = append(col, dec.Read())
+		}
+		cols[j] = col
+		b = b[size:]
+	}
+	return cols, nil
+}
+
+// EncodePairResidual encodes two correlated columns of equal length by storing
+// a as is and b as the zigzag encoded residual b[i]-a[i], which stays small when
+// the columns track each other.  The two streams are framed as by EncodeColumns.
+func EncodePairResidual(a, b []uint64) ([]byte, error) {
+	if len(a) != len(b) {
+		return nil, fmt.Errorf("column length mismatch: %v != %v", len(a), len(b))
+	}
+
+	residuals := make([]uint64, len(b))
+	for i := range b {
+		residuals[i] = bitops.ZigZagEncode64(int64(b[i] - a[i]))
+	}
+	return EncodeColumns([][]uint64{a, residuals})
+}
+
+// DecodePairResidual returns the columns encoded by EncodePairResidual.
+func DecodePairResidual(buf []byte) (a, b []uint64, err error) {
+	cols, err := DecodeColumns(buf)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(cols) != 2 || len(cols[0]) != len(cols[1]) {
+		return nil, nil, fmt.Errorf("invalid column pair")
+	}
+
+	a, b = cols[0], cols[1]
+	for i, r := range b {
+		b[i] = a[i] + uint64(bitops.ZigZagDecode64(r))
+	}
+	return a, b, nil
+}
+
+// LayoutAdvice estimates the encoded size of cols stored as given, with each
+// column packed on its own, and transposed to row-major, with the values of
+// each row interleaved in one stream.  transpose is true if the row-major
+// layout is smaller, and est is the estimated size in bytes of the recommended
+// layout.  Columns of different lengths are interleaved until each runs out.
+func LayoutAdvice(cols [][]uint64) (transpose bool, est int) {
+	var (
+		columnMajor int
+		rows        int
+	)
+	for _, col := range cols {
+		columnMajor += simple8b.EstimateBytes(col)
+		if len(col) > rows {
+			rows = len(col)
+		}
+	}
+
+	interleaved := make([]uint64, 0, rows*len(cols))
+	for i := 0; i < rows; i++ {
+		for _, col := range cols {
+			if i < len(col) {
+				interleaved = append(interleaved, col[i])
+			}
+		}
+	}
+	rowMajor := simple8b.EstimateBytes(interleaved)
+
+	if rowMajor < columnMajor {
+		return true, rowMajor
+	}
+	return false, columnMajor
+}
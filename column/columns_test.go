@@ -0,0 +1,139 @@
+package column_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jwilder/encoding/column"
+)
+
+func TestEncodeColumns(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	// A constant flag, a small counter and a wide random id per record
+	cols := make([][]uint64, 3)
+	for i := 0; i < 1000; i++ {
+		cols[0] = append(cols[0], 1)
+		cols[1] = append(cols[1], uint64(i%100))
+		cols[2] = append(cols[2], uint64(rng.Int63n(1<<40)))
+	}
+
+	b, err := column.EncodeColumns(cols)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out, err := column.DecodeColumns(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(cols), len(out); got != exp {
+		t.Fatalf("Column count mismatch: exp %v, got %v", exp, got)
+	}
+
+	for c := range cols {
+		if exp, got := len(cols[c]), len(out[c]); got != exp {
+			t.Fatalf("Column[%d] len mismatch: exp %v, got %v", c, exp, got)
+		}
+
+		for i := range cols[c] {
+			if out[c][i] != cols[c][i] {
+				t.Fatalf("Column[%d] decoded[%d] != %v, got %v", c, i, cols[c][i], out[c][i])
+			}
+		}
+	}
+
+	if _, err := column.DecodeColumns(b[:len(b)-1]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestEncodePairResidual(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+
+	// Two sensors reading the same slowly varying signal with small noise
+	a := make([]uint64, 1000)
+	b := make([]uint64, 1000)
+	signal := uint64(1 << 30)
+	for i := 0; i < len(a); i++ {
+		signal += uint64(rng.Int63n(1<<10)) - 1<<9
+		a[i] = signal
+		b[i] = signal + uint64(rng.Int63n(16)) - 8
+	}
+
+	buf, err := column.EncodePairResidual(a, b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	independent, err := column.EncodeColumns([][]uint64{a, b})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(buf) >= len(independent) {
+		t.Fatalf("Expected residual encoding to be smaller: got %v, independent %v", len(buf), len(independent))
+	}
+
+	outA, outB, err := column.DecodePairResidual(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(outA) != len(a) || len(outB) != len(b) {
+		t.Fatalf("Decode len mismatch: exp %v, got %v and %v", len(a), len(outA), len(outB))
+	}
+
+	for i := range a {
+		if outA[i] != a[i] {
+			t.Fatalf("DecodedA[%d] != %v, got %v", i, a[i], outA[i])
+		}
+		if outB[i] != b[i] {
+			t.Fatalf("DecodedB[%d] != %v, got %v", i, b[i], outB[i])
+		}
+	}
+
+	if _, err := column.EncodePairResidual(a, b[:10]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestLayoutAdvice(t *testing.T) {
+	// A boolean flag next to a wide id: interleaving forces every flag into
+	// the wide words used by the ids.
+	cols := make([][]uint64, 2)
+	for i := 0; i < 1000; i++ {
+		cols[0] = append(cols[0], uint64(i%2))
+		cols[1] = append(cols[1], uint64(1<<40+i))
+	}
+
+	transpose, est := column.LayoutAdvice(cols)
+	if transpose {
+		t.Fatalf("Expected column-major layout to be recommended")
+	}
+
+	b, err := column.EncodeColumns(cols)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The framed buffer only adds a small directory to the packed columns
+	if est > len(b) || len(b)-est > 8 {
+		t.Fatalf("Estimate mismatch: exp ~%v, got %v", len(b), est)
+	}
+}
+
+func TestLayoutAdvice_RowMajor(t *testing.T) {
+	// Two columns of 60 ones each need a word of their own, but interleaved
+	// they form a single run of 120 ones
+	cols := make([][]uint64, 2)
+	for i := 0; i < 60; i++ {
+		cols[0] = append(cols[0], 1)
+		cols[1] = append(cols[1], 1)
+	}
+
+	if transpose, est := column.LayoutAdvice(cols); !transpose || est != 8 {
+		t.Fatalf("LayoutAdvice mismatch: exp true, 8, got %v, %v", transpose, est)
+	}
+}
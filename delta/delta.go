@@ -0,0 +1,507 @@
+// Package delta implements delta encoding of integer slices.  Delta encoding
+// stores the difference between consecutive values instead of the values
+// themselves, which produces small values for slowly changing series that
+// compress well with the simple8b and simple9 encoders.
+package delta
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"math/bits"
+
+	"github.com/jwilder/encoding/bitops"
+	"github.com/jwilder/encoding/simple8b"
+)
+
+// DeltaUint writes the delta encoding of src to dst.  The first value of src
+// is stored as is and each subsequent value is stored as the difference from
+// the previous value.  Differences use wrapping (modulo 2^64) arithmetic so
+// unsigned counters that roll over still produce small deltas.  dst must be at
+// least as long as src and may be the same slice as src.
+func DeltaUint(dst, src []uint64) {
+	var prev uint64
+	for i, v := range src {
+		dst[i] = v - prev
+		prev = v
+	}
+}
+
+// InverseDeltaUint reverses DeltaUint, writing the original values encoded in
+// src to dst.  dst must be at least as long as src and may be the same slice as
+// src.
+func InverseDeltaUint(dst, src []uint64) {
+	var prev uint64
+	for i, v := range src {
+		prev += v
+		dst[i] = prev
+	}
+}
+
+// CommonDivisor returns the greatest common divisor of the differences between
+// consecutive values in src.  Dividing the deltas by this value before encoding
+// shrinks them while still allowing exact reconstruction.  It returns 1 if src
+// has fewer than two values or all of the deltas are zero.  If every delta is
+// math.MinInt64, whose magnitude does not fit in an int64, it returns 1<<62.
+func CommonDivisor(src []int64) int64 {
+	var d uint64
+	for i := 1; i < len(src); i++ {
+		// Negating as uint64 gives the magnitude even for math.MinInt64
+		v := uint64(src[i] - src[i-1])
+		if int64(v) < 0 {
+			v = -v
+		}
+		d = gcd(d, v)
+		if d == 1 {
+			break
+		}
+	}
+
+	if d == 0 {
+		return 1
+	} else if d > math.MaxInt64 {
+		d >>= 1
+	}
+	return int64(d)
+}
+
+// gcd returns the greatest common divisor of a and b.
+func gcd(a, b uint64) uint64 {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
+
+// Rebase changes the starting value of the delta encoded values in src to
+// newFirst.  Since only the first value of a delta encoding is absolute, every
+// reconstructed value is shifted by the same offset without touching the
+// remaining deltas.
+func Rebase(src []int64, newFirst int64) {
+	if len(src) == 0 {
+		return
+	}
+	src[0] = newFirst
+}
+
+// SizeOf returns the number of bytes DeltaFramed needs to store src: the first
+// value as 8 bytes followed by the zigzag encoded deltas packed with simple8b.
+// The words are counted rather than serialized so no output buffer is produced.
+func SizeOf(src []int64) (int, error) {
+	if len(src) == 0 {
+		return 0, nil
+	}
+
+	values := make([]uint64, len(src)-1)
+	for i := 1; i < len(src); i++ {
+		values[i-1] = bitops.ZigZagEncode64(src[i] - src[i-1])
+		if values[i-1] > simple8b.MaxValue {
+			return 0, fmt.Errorf("value out of bounds: %v", values[i-1])
+		}
+	}
+	return 8 + simple8b.EstimateBytes(values), nil
+}
+
+// rleSamples is the number of leading deltas examined by ShouldRLE
+const rleSamples = 16
+
+// ShouldRLE returns true if src looks like it could be run length encoded after
+// delta encoding.  Only the first few deltas and the final delta are compared,
+// so a false result reliably rules out RLE cheaply while a true result should
+// still be confirmed by a full pass.
+func ShouldRLE(src []int64) bool {
+	if len(src) < 2 {
+		return false
+	}
+
+	d := src[1] - src[0]
+	for i := 2; i < len(src) && i <= rleSamples; i++ {
+		if src[i]-src[i-1] != d {
+			return false
+		}
+	}
+
+	return src[len(src)-1]-src[len(src)-2] == d
+}
+
+// DeltaStride writes the delta encoding of src to dst where each value is
+// differenced against the value stride positions before it.  This delta encodes
+// each of the stride interleaved lanes of row-major data independently.  The
+// first stride values are stored as is.  A stride less than 1 is treated as 1.
+// dst must be at least as long as src and may be the same slice as src.
+func DeltaStride(dst, src []int64, stride int) {
+	if stride < 1 {
+		stride = 1
+	}
+
+	for i := len(src) - 1; i >= 0; i-- {
+		if i < stride {
+			dst[i] = src[i]
+			continue
+		}
+		dst[i] = src[i] - src[i-stride]
+	}
+}
+
+// InverseDeltaStride reverses DeltaStride, writing the original values encoded
+// in src to dst.  A stride less than 1 is treated as 1.  dst must be at least as
+// long as src and may be the same slice as src.
+func InverseDeltaStride(dst, src []int64, stride int) {
+	if stride < 1 {
+		stride = 1
+	}
+
+	for i, v := range src {
+		if i < stride {
+			dst[i] = v
+			continue
+		}
+		dst[i] = v + dst[i-stride]
+	}
+}
+
+// DeltaBoth returns the single and double delta encodings of src computed in
+// one pass.  d1 matches DeltaStride with a stride of 1 and d2 is the same
+// encoding applied to d1, so the first value of each is src[0].
+func DeltaBoth(src []int64) (d1, d2 []int64) {
+	d1 = make([]int64, len(src))
+	d2 = make([]int64, len(src))
+
+	var prev, prevDelta int64
+	for i, v := range src {
+		d := v - prev
+		d1[i] = d
+		d2[i] = d - prevDelta
+		prev, prevDelta = v, d
+	}
+	return d1, d2
+}
+
+// maxDescriptorValues is the largest count EncodeArithmetic and EncodeRLE will
+// write.  A descriptor is a few bytes no matter how many values it describes,
+// so DecodeArithmetic and DecodeRLE reject larger counts rather than allocate a
+// slice sized by a corrupt varint.
+const maxDescriptorValues = 1 << 28
+
+// EncodeArithmetic returns a compact descriptor of src if it is an arithmetic
+// sequence, where every value differs from the previous one by the same stride.
+// The descriptor holds the first value, the stride and the count as varints.  If
+// src is empty, not arithmetic or longer than DecodeArithmetic accepts, it
+// returns false and no bytes so the caller can fall back to another encoding.
+func EncodeArithmetic(src []int64) ([]byte, bool) {
+	if len(src) == 0 || len(src) > maxDescriptorValues {
+		return nil, false
+	}
+
+	var stride int64
+	if len(src) > 1 {
+		stride = src[1] - src[0]
+	}
+	for i := 2; i < len(src); i++ {
+		if src[i]-src[i-1] != stride {
+			return nil, false
+		}
+	}
+
+	b := make([]byte, 3*binary.MaxVarintLen64)
+	n := binary.PutVarint(b, src[0])
+	n += binary.PutVarint(b[n:], stride)
+	n += binary.PutUvarint(b[n:], uint64(len(src)))
+	return b[:n], true
+}
+
+// DecodeArithmetic returns the sequence described by a descriptor produced by
+// EncodeArithmetic.
+func DecodeArithmetic(b []byte) ([]int64, error) {
+	first, n := binary.Varint(b)
+	if n <= 0 {
+		return nil, fmt.Errorf("unable to decode first value")
+	}
+	b = b[n:]
+
+	stride, n := binary.Varint(b)
+	if n <= 0 {
+		return nil, fmt.Errorf("unable to decode stride")
+	}
+	b = b[n:]
+
+	count, n := binary.Uvarint(b)
+	if n <= 0 {
+		return nil, fmt.Errorf("unable to decode count")
+	}
+
+	if count > maxDescriptorValues {
+		return nil, fmt.Errorf("count too large: %v > %v", count, maxDescriptorValues)
+	}
+
+	dst := make([]int64, count)
+	v := first
+	for i := range dst {
+		dst[i] = v
+		v += stride
+	}
+	return dst, nil
+}
+
+// DeltaFramed delta encodes src, storing the first value as 8 big endian bytes
+// followed by the zigzag encoded deltas of the remaining values packed with
+// simple8b.  Keeping the first value out of the packed words means a large
+// starting value, such as a nanosecond timestamp, never needs a wide word and
+// is not limited to the simple8b value range.  An empty src returns no bytes.
+func DeltaFramed(src []int64) ([]byte, error) {
+	if len(src) == 0 {
+		return nil, nil
+	}
+
+	enc := simple8b.NewEncoder()
+	for i := 1; i < len(src); i++ {
+		if err := enc.Write(bitops.ZigZagEncode64(src[i] - src[i-1])); err != nil {
+			return nil, err
+		}
+	}
+
+	words, err := enc.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 8, 8+len(words))
+	binary.BigEndian.PutUint64(b, uint64(src[0]))
+	return append(b, words...), nil
+}
+
+// InverseDeltaFramed returns the values encoded by DeltaFramed.
+func InverseDeltaFramed(b []byte) ([]int64, error) {
+	if len(b) == 0 {
+		return nil, nil
+	}
+
+	if len(b) < 8 {
+		return nil, fmt.Errorf("unable to decode first value: %v bytes", len(b))
+	}
+
+	n, err := simple8b.CountBytes(b[8:])
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]int64, 1, n+1)
+	dst[0] = int64(binary.BigEndian.Uint64(b))
+
+	dec := simple8b.NewDecoder(b[8:])
+	for dec.Next() {
+		dst = append(dst, dst[len(dst)-1]+bitops.ZigZagDecode64(dec.Read()))
+	}
+	return dst, nil
+}
+
+// DecodeDelta decodes a simple8b buffer of zigzag encoded deltas and returns
+// the running sum of the deltas starting from first.  Each delta is relative to
+// the previous value, with first standing in for the value before the buffer,
+// so a buffer from EncodeCheckpointed decodes with the first checkpoint and
+// returns the values after it.
+func DecodeDelta(b []byte, first int64) ([]int64, error) {
+	n, err := simple8b.CountBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	dst := make([]int64, 0, n)
+	prev := first
+	err = simple8b.EachWord(b, func(vals []uint64) {
+		for _, v := range vals {
+			prev += bitops.ZigZagDecode64(v)
+			dst = append(dst, prev)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dst, nil
+}
+
+// EncodeRLE returns the run length encoding of count values starting at first
+// and increasing by delta.  Timestamps are often multiples of a power of 10, so
+// delta is stored divided by mod to keep it short.  The output holds first as 8
+// big endian bytes followed by mod, delta/mod and count as uvarints.  delta must
+// be a non-negative multiple of mod, mod must be positive and count must be no
+// larger than DecodeRLE accepts.
+func EncodeRLE(first, delta, mod int64, count int) ([]byte, error) {
+	if mod <= 0 {
+		return nil, fmt.Errorf("invalid mod: %v", mod)
+	}
+
+	if delta < 0 || delta%mod != 0 {
+		return nil, fmt.Errorf("delta %v is not a non-negative multiple of %v", delta, mod)
+	}
+
+	if count < 0 {
+		return nil, fmt.Errorf("invalid count: %v", count)
+	}
+
+	if count > maxDescriptorValues {
+		return nil, fmt.Errorf("count too large: %v > %v", count, maxDescriptorValues)
+	}
+
+	b := make([]byte, 8+3*binary.MaxVarintLen64)
+	binary.BigEndian.PutUint64(b, uint64(first))
+	n := 8
+	n += binary.PutUvarint(b[n:], uint64(mod))
+	n += binary.PutUvarint(b[n:], uint64(delta/mod))
+	n += binary.PutUvarint(b[n:], uint64(count))
+	return b[:n], nil
+}
+
+// DecodeRLE returns the values described by a run length encoding produced by
+// EncodeRLE.
+func DecodeRLE(b []byte) ([]int64, error) {
+	if len(b) < 8 {
+		return nil, fmt.Errorf("unable to decode first value: %v bytes", len(b))
+	}
+	first := int64(binary.BigEndian.Uint64(b))
+	b = b[8:]
+
+	mod, n := binary.Uvarint(b)
+	if n <= 0 {
+		return nil, fmt.Errorf("unable to decode mod")
+	}
+	b = b[n:]
+
+	delta, n := binary.Uvarint(b)
+	if n <= 0 {
+		return nil, fmt.Errorf("unable to decode delta")
+	}
+	b = b[n:]
+
+	count, n := binary.Uvarint(b)
+	if n <= 0 {
+		return nil, fmt.Errorf("unable to decode count")
+	}
+
+	if mod == 0 || mod > math.MaxInt64 {
+		return nil, fmt.Errorf("invalid mod: %v", mod)
+	}
+
+	if delta > math.MaxInt64/mod {
+		return nil, fmt.Errorf("delta overflows: %v * %v", delta, mod)
+	}
+
+	if count > maxDescriptorValues {
+		return nil, fmt.Errorf("count too large: %v > %v", count, maxDescriptorValues)
+	}
+
+	dst := make([]int64, count)
+	v, stride := first, int64(delta*mod)
+	for i := range dst {
+		dst[i] = v
+		v += stride
+	}
+	return dst, nil
+}
+
+// DeltaBenefit returns the number of bits needed to store the largest value of
+// src and the largest delta between consecutive values of src.  Both are
+// measured after zigzag encoding so negative values are handled the same way.
+// If deltaBits is not less than rawBits, delta encoding does not help.
+func DeltaBenefit(src []int64) (rawBits, deltaBits int) {
+	for i, v := range src {
+		if n := bits.Len64(bitops.ZigZagEncode64(v)); n > rawBits {
+			rawBits = n
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		if n := bits.Len64(bitops.ZigZagEncode64(v - src[i-1])); n > deltaBits {
+			deltaBits = n
+		}
+	}
+	return rawBits, deltaBits
+}
+
+// DeltaWidthProfile returns the number of deltas between consecutive values of
+// src requiring each bit width from 0 to 60 once zigzag encoded.  The profile
+// predicts the selectors simple8b will use for the delta stream.  Deltas wider
+// than 60 bits cannot be packed and are not counted.
+func DeltaWidthProfile(src []int64) [61]int {
+	if len(src) < 2 {
+		return [61]int{}
+	}
+
+	deltas := make([]uint64, len(src)-1)
+	for i := 1; i < len(src); i++ {
+		deltas[i-1] = bitops.ZigZagEncode64(src[i] - src[i-1])
+	}
+	return bitops.ClassifyWidths(deltas)
+}
+
+// DeltaMod writes the delta encoding of src to dst for values that wrap at mod,
+// such as angles.  Each delta is reduced modulo mod to the signed value with the
+// smallest magnitude, so a step from 359 to 1 with a mod of 360 is stored as +2
+// rather than -358.  Values in src must be in the range [0, mod).  If mod is not
+// positive, values do not wrap and the plain deltas are stored.  dst must be at
+// least as long as src and may be the same slice as src.
+func DeltaMod(dst, src []int64, mod int64) {
+	var prev int64
+	for i, v := range src {
+		if i == 0 {
+			dst[i] = v
+			prev = v
+			continue
+		}
+
+		if mod <= 0 {
+			dst[i] = v - prev
+			prev = v
+			continue
+		}
+
+		d := (v - prev) % mod
+		if d < 0 {
+			d += mod
+		}
+		if d > mod/2 {
+			d -= mod
+		}
+		dst[i] = d
+		prev = v
+	}
+}
+
+// InverseDeltaMod reverses DeltaMod, writing the original values encoded in src
+// to dst.  mod must match the value passed to DeltaMod.  dst must be at least as
+// long as src and may be the same slice as src.
+func InverseDeltaMod(dst, src []int64, mod int64) {
+	var prev int64
+	for i, v := range src {
+		if i == 0 {
+			prev = v
+		} else if mod <= 0 {
+			prev += v
+		} else {
+			prev = (prev + v) % mod
+			if prev < 0 {
+				prev += mod
+			}
+		}
+		dst[i] = prev
+	}
+}
+
+// DeltaColumns writes the element-wise difference a[i]-b[i] to dst, storing
+// column a relative to a reference column b.  dst, a and b must be the same
+// length and dst may be the same slice as a.
+func DeltaColumns(dst, a, b []int64) {
+	for i, v := range a {
+		dst[i] = v - b[i]
+	}
+}
+
+// InverseDeltaColumns reverses DeltaColumns, writing delta[i]+b[i] to dst.  dst
+// may be the same slice as delta.
+func InverseDeltaColumns(dst, delta, b []int64) {
+	for i, v := range delta {
+		dst[i] = v + b[i]
+	}
+}
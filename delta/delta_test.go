@@ -0,0 +1,679 @@
+package delta_test
+
+import (
+	"encoding/binary"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/jwilder/encoding/delta"
+)
+
+func TestDeltaUint_Wraparound(t *testing.T) {
+	in := []uint64{math.MaxUint64 - 10, math.MaxUint64 - 5, math.MaxUint64, 2, 7, 12}
+
+	deltas := make([]uint64, len(in))
+	delta.DeltaUint(deltas, in)
+
+	exp := []uint64{math.MaxUint64 - 10, 5, 5, 3, 5, 5}
+	for i := 0; i < len(exp); i++ {
+		if deltas[i] != exp[i] {
+			t.Fatalf("Delta[%d] != %v, got %v", i, exp[i], deltas[i])
+		}
+	}
+
+	decoded := make([]uint64, len(deltas))
+	delta.InverseDeltaUint(decoded, deltas)
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+}
+
+func TestDeltaUint_InPlace(t *testing.T) {
+	in := []uint64{100, 110, 120, 125, 200}
+	values := make([]uint64, len(in))
+	copy(values, in)
+
+	delta.DeltaUint(values, values)
+	delta.InverseDeltaUint(values, values)
+
+	for i := 0; i < len(in); i++ {
+		if values[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], values[i])
+		}
+	}
+}
+
+func TestCommonDivisor(t *testing.T) {
+	in := make([]int64, 100)
+	in[0] = 1000000
+	for i := 1; i < len(in); i++ {
+		// Strides of 250, 500 and 750 all share a divisor of 250
+		in[i] = in[i-1] + int64(250*(1+i%3))
+	}
+
+	if exp, got := int64(250), delta.CommonDivisor(in); got != exp {
+		t.Fatalf("CommonDivisor mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestCommonDivisor_Decreasing(t *testing.T) {
+	in := []int64{4096, 3072, 2048, 3072, 0}
+	if exp, got := int64(1024), delta.CommonDivisor(in); got != exp {
+		t.Fatalf("CommonDivisor mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestCommonDivisor_MinInt64(t *testing.T) {
+	tests := []struct {
+		in  []int64
+		exp int64
+	}{
+		{[]int64{0, math.MinInt64}, 1 << 62},
+		{[]int64{0, 6, math.MinInt64 + 6}, 2},
+		{[]int64{math.MinInt64, 0, -1 << 40}, 1 << 40},
+	}
+
+	for _, test := range tests {
+		if got := delta.CommonDivisor(test.in); got != test.exp {
+			t.Fatalf("CommonDivisor(%v) mismatch: exp %v, got %v", test.in, test.exp, got)
+		}
+	}
+}
+
+func TestCommonDivisor_NoDeltas(t *testing.T) {
+	if exp, got := int64(1), delta.CommonDivisor([]int64{5}); got != exp {
+		t.Fatalf("CommonDivisor mismatch: exp %v, got %v", exp, got)
+	}
+
+	if exp, got := int64(1), delta.CommonDivisor([]int64{5, 5, 5}); got != exp {
+		t.Fatalf("CommonDivisor mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestRebase(t *testing.T) {
+	// 1000, 1010, 1015, 1005, 1030 delta encoded
+	src := []int64{1000, 10, 5, -10, 25}
+	before := prefixSum(src)
+
+	delta.Rebase(src, 50)
+	after := prefixSum(src)
+
+	if exp, got := int64(50), after[0]; got != exp {
+		t.Fatalf("First value mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(before); i++ {
+		if exp, got := int64(-950), after[i]-before[i]; got != exp {
+			t.Fatalf("Offset[%d] mismatch: exp %v, got %v", i, exp, got)
+		}
+	}
+}
+
+func prefixSum(src []int64) []int64 {
+	dst := make([]int64, len(src))
+	var prev int64
+	for i, v := range src {
+		prev += v
+		dst[i] = prev
+	}
+	return dst
+}
+
+func TestSizeOf(t *testing.T) {
+	in := make([]int64, 1000)
+	in[0] = 1000000
+	for i := 1; i < len(in); i++ {
+		in[i] = in[i-1] + 10 + int64(i%7) - 3
+	}
+
+	got, err := delta.SizeOf(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b, err := delta.DeltaFramed(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp := len(b); got != exp {
+		t.Fatalf("SizeOf mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestSizeOf_Timestamps(t *testing.T) {
+	in := make([]int64, 1000)
+	in[0] = 1600000000000000000
+	for i := 1; i < len(in); i++ {
+		in[i] = in[i-1] + 1000000000 + int64(i%7)
+	}
+
+	got, err := delta.SizeOf(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b, err := delta.DeltaFramed(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp := len(b); got != exp {
+		t.Fatalf("SizeOf mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestSizeOf_TooLarge(t *testing.T) {
+	if _, err := delta.SizeOf([]int64{0, math.MaxInt64}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestShouldRLE(t *testing.T) {
+	in := make([]int64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = 1000 + int64(i)*10
+	}
+
+	if !delta.ShouldRLE(in) {
+		t.Fatalf("ShouldRLE mismatch: exp true, got false")
+	}
+}
+
+func TestShouldRLE_Jitter(t *testing.T) {
+	in := make([]int64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = 1000 + int64(i)*10 + int64(i%3)
+	}
+
+	if delta.ShouldRLE(in) {
+		t.Fatalf("ShouldRLE mismatch: exp false, got true")
+	}
+
+	if delta.ShouldRLE(in[:1]) {
+		t.Fatalf("ShouldRLE mismatch for single value: exp false, got true")
+	}
+}
+
+func TestDeltaStride(t *testing.T) {
+	// 10 rows of 4 interleaved fields with very different magnitudes
+	in := make([]int64, 40)
+	for i := 0; i < len(in); i++ {
+		row, lane := int64(i/4), i%4
+		switch lane {
+		case 0:
+			in[i] = 1000000 + row*10
+		case 1:
+			in[i] = -500 - row
+		case 2:
+			in[i] = 7
+		case 3:
+			in[i] = row * row
+		}
+	}
+
+	deltas := make([]int64, len(in))
+	delta.DeltaStride(deltas, in, 4)
+
+	for i := 4; i < len(deltas); i++ {
+		if exp := in[i] - in[i-4]; deltas[i] != exp {
+			t.Fatalf("Delta[%d] != %v, got %v", i, exp, deltas[i])
+		}
+	}
+
+	decoded := make([]int64, len(in))
+	delta.InverseDeltaStride(decoded, deltas, 4)
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+
+	// In place
+	values := make([]int64, len(in))
+	copy(values, in)
+	delta.DeltaStride(values, values, 4)
+	delta.InverseDeltaStride(values, values, 4)
+	for i := 0; i < len(in); i++ {
+		if values[i] != in[i] {
+			t.Fatalf("Decoded in place[%d] != %v, got %v", i, in[i], values[i])
+		}
+	}
+}
+
+func TestDeltaStride_NonPositive(t *testing.T) {
+	in := []int64{5, 8, 6, 10, 10}
+
+	exp := make([]int64, len(in))
+	delta.DeltaStride(exp, in, 1)
+
+	for _, stride := range []int{0, -3} {
+		deltas := make([]int64, len(in))
+		delta.DeltaStride(deltas, in, stride)
+		for i := range deltas {
+			if deltas[i] != exp[i] {
+				t.Fatalf("Delta[%d] != %v, got %v for stride %v", i, exp[i], deltas[i], stride)
+			}
+		}
+
+		decoded := make([]int64, len(in))
+		delta.InverseDeltaStride(decoded, deltas, stride)
+		for i := range decoded {
+			if decoded[i] != in[i] {
+				t.Fatalf("Decoded[%d] != %v, got %v for stride %v", i, in[i], decoded[i], stride)
+			}
+		}
+	}
+}
+
+func TestDeltaBoth(t *testing.T) {
+	in := make([]int64, 100)
+	for i := 0; i < len(in); i++ {
+		in[i] = 1000 + int64(i*i) - int64(i%3)
+	}
+
+	d1, d2 := delta.DeltaBoth(in)
+
+	exp1 := make([]int64, len(in))
+	delta.DeltaStride(exp1, in, 1)
+	exp2 := make([]int64, len(in))
+	delta.DeltaStride(exp2, exp1, 1)
+
+	if len(d1) != len(in) || len(d2) != len(in) {
+		t.Fatalf("Delta len mismatch: exp %v, got %v and %v", len(in), len(d1), len(d2))
+	}
+
+	for i := 0; i < len(in); i++ {
+		if d1[i] != exp1[i] {
+			t.Fatalf("Delta[%d] != %v, got %v", i, exp1[i], d1[i])
+		}
+		if d2[i] != exp2[i] {
+			t.Fatalf("Double delta[%d] != %v, got %v", i, exp2[i], d2[i])
+		}
+	}
+}
+
+func TestEncodeArithmetic(t *testing.T) {
+	in := make([]int64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = 1442369134000000000 + int64(i)*10000000000
+	}
+
+	b, ok := delta.EncodeArithmetic(in)
+	if !ok {
+		t.Fatalf("EncodeArithmetic mismatch: exp true, got false")
+	}
+
+	if len(b) > 3*binary.MaxVarintLen64 {
+		t.Fatalf("Descriptor too large: got %v bytes", len(b))
+	}
+
+	decoded, err := delta.DecodeArithmetic(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), len(decoded); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+}
+
+func TestEncodeArithmetic_NearlyArithmetic(t *testing.T) {
+	in := make([]int64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = int64(i) * 5
+	}
+	in[999]++
+
+	if b, ok := delta.EncodeArithmetic(in); ok || b != nil {
+		t.Fatalf("EncodeArithmetic mismatch: exp false, got %v, %v", ok, b)
+	}
+}
+
+func TestDecodeArithmetic_Invalid(t *testing.T) {
+	b := make([]byte, 3*binary.MaxVarintLen64)
+	n := binary.PutVarint(b, 1)
+	n += binary.PutVarint(b[n:], 1)
+	m := n + binary.PutUvarint(b[n:], math.MaxUint64)
+	if _, err := delta.DecodeArithmetic(b[:m]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	m = n + binary.PutUvarint(b[n:], 1<<28+1)
+	if _, err := delta.DecodeArithmetic(b[:m]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	if _, err := delta.DecodeArithmetic(b[:n]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestDeltaFramed(t *testing.T) {
+	in := make([]int64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = 1<<50 + int64(i*10) + int64(i%3)
+	}
+
+	b, err := delta.DeltaFramed(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	size, err := delta.SizeOf(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp := len(b); size != exp {
+		t.Fatalf("SizeOf mismatch: exp %v, got %v", exp, size)
+	}
+
+	out, err := delta.InverseDeltaFramed(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), len(out); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if out[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], out[i])
+		}
+	}
+}
+
+func TestDeltaFramed_LargeFirst(t *testing.T) {
+	// Nanosecond timestamps are too large to pack once zigzag encoded
+	in := []int64{1600000000000000000, 1600000000000000010, 1600000000000000020}
+	b, err := delta.DeltaFramed(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 16, len(b); got != exp {
+		t.Fatalf("Encoded len mismatch: exp %v, got %v", exp, got)
+	}
+
+	out, err := delta.InverseDeltaFramed(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if out[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], out[i])
+		}
+	}
+}
+
+func TestDecodeDelta(t *testing.T) {
+	in := make([]int64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = 5000 + int64(i*3) - int64(i%5)
+	}
+
+	b, checkpoints, err := delta.EncodeCheckpointed(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out, err := delta.DecodeDelta(b, checkpoints[0])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in)-1, len(out); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(out); i++ {
+		if out[i] != in[i+1] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i+1], out[i])
+		}
+	}
+
+	// Deltas relative to a first value stored elsewhere
+	framed, err := delta.DeltaFramed(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out, err = delta.DecodeDelta(framed[8:], in[0])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in)-1, len(out); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(out); i++ {
+		if out[i] != in[i+1] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i+1], out[i])
+		}
+	}
+}
+
+func TestEncodeRLE(t *testing.T) {
+	first := int64(1600000000000000000)
+	b, err := delta.EncodeRLE(first, 10000000000, 1000000000, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 8+5+1+2, len(b); got != exp {
+		t.Fatalf("Encoded len mismatch: exp %v, got %v", exp, got)
+	}
+
+	out, err := delta.DecodeRLE(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 1000, len(out); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i, v := range out {
+		if exp := first + int64(i)*10000000000; v != exp {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, exp, v)
+		}
+	}
+}
+
+func TestEncodeRLE_Invalid(t *testing.T) {
+	if _, err := delta.EncodeRLE(0, 15, 10, 5); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	if _, err := delta.EncodeRLE(0, 10, 0, 5); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	if _, err := delta.EncodeRLE(0, 10, 10, 1<<28+1); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	if _, err := delta.DecodeRLE([]byte{1, 2, 3}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+// rleDescriptor builds an RLE descriptor by hand so invalid fields can be
+// written.
+func rleDescriptor(first int64, mod, delta, count uint64) []byte {
+	b := make([]byte, 8+3*binary.MaxVarintLen64)
+	binary.BigEndian.PutUint64(b, uint64(first))
+	n := 8
+	n += binary.PutUvarint(b[n:], mod)
+	n += binary.PutUvarint(b[n:], delta)
+	n += binary.PutUvarint(b[n:], count)
+	return b[:n]
+}
+
+func TestDecodeRLE_Invalid(t *testing.T) {
+	tests := []struct {
+		name              string
+		mod, delta, count uint64
+	}{
+		{"zero mod", 0, 1, 10},
+		{"mod overflows int64", math.MaxUint64, 0, 10},
+		{"delta times mod overflows", 1000000000, 1 << 40, 10},
+		{"count too large", 1, 1, 1<<28 + 1},
+		{"count out of range", 1, 1, math.MaxUint64},
+	}
+
+	for _, test := range tests {
+		if _, err := delta.DecodeRLE(rleDescriptor(0, test.mod, test.delta, test.count)); err == nil {
+			t.Fatalf("%s: expected error, got nil", test.name)
+		}
+	}
+
+	// Truncated at each field
+	b := rleDescriptor(0, 1000, 1000, 1000)
+	for _, n := range []int{0, 7, 8, 10, 12} {
+		if _, err := delta.DecodeRLE(b[:n]); err == nil {
+			t.Fatalf("Expected error for %v bytes, got nil", n)
+		}
+	}
+}
+
+func TestDeltaBenefit_Clustered(t *testing.T) {
+	in := make([]int64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = 1<<40 + int64(i*3%17)
+	}
+
+	rawBits, deltaBits := delta.DeltaBenefit(in)
+	if exp := 42; rawBits != exp {
+		t.Fatalf("Raw bits mismatch: exp %v, got %v", exp, rawBits)
+	}
+
+	if deltaBits >= rawBits {
+		t.Fatalf("Expected delta to help: raw %v, delta %v", rawBits, deltaBits)
+	}
+}
+
+func TestDeltaBenefit_Random(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	in := make([]int64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = rnd.Int63n(1 << 40)
+	}
+
+	rawBits, deltaBits := delta.DeltaBenefit(in)
+	if deltaBits < rawBits {
+		t.Fatalf("Expected delta not to help: raw %v, delta %v", rawBits, deltaBits)
+	}
+}
+
+func TestDeltaWidthProfile(t *testing.T) {
+	// A 60s stride with a little jitter
+	in := make([]int64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = 1<<40 + int64(i*60) + int64(i%3)
+	}
+
+	widths := delta.DeltaWidthProfile(in)
+
+	var total, low int
+	for w, n := range widths {
+		total += n
+		if w <= 7 {
+			low += n
+		}
+	}
+
+	if exp := len(in) - 1; total != exp {
+		t.Fatalf("Delta count mismatch: exp %v, got %v", exp, total)
+	}
+
+	if low != total {
+		t.Fatalf("Expected all deltas within 7 bits, got %v of %v", low, total)
+	}
+
+	if widths[7] == 0 {
+		t.Fatalf("Expected deltas at 7 bits, got %v", widths)
+	}
+}
+
+func TestDeltaMod(t *testing.T) {
+	in := []int64{350, 355, 359, 1, 5, 3, 358, 340, 0}
+
+	deltas := make([]int64, len(in))
+	delta.DeltaMod(deltas, in, 360)
+
+	exp := []int64{350, 5, 4, 2, 4, -2, -5, -18, 20}
+	for i := 0; i < len(exp); i++ {
+		if deltas[i] != exp[i] {
+			t.Fatalf("Delta[%d] != %v, got %v", i, exp[i], deltas[i])
+		}
+	}
+
+	decoded := make([]int64, len(in))
+	delta.InverseDeltaMod(decoded, deltas, 360)
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+}
+
+func TestDeltaMod_NonPositive(t *testing.T) {
+	in := []int64{350, 355, 359, 1, 5, 3, 358, 340, 0}
+
+	for _, mod := range []int64{0, -360} {
+		deltas := make([]int64, len(in))
+		delta.DeltaMod(deltas, in, mod)
+		for i := 1; i < len(in); i++ {
+			if exp := in[i] - in[i-1]; deltas[i] != exp {
+				t.Fatalf("Delta[%d] != %v, got %v for mod %v", i, exp, deltas[i], mod)
+			}
+		}
+
+		decoded := make([]int64, len(in))
+		delta.InverseDeltaMod(decoded, deltas, mod)
+		for i := 0; i < len(in); i++ {
+			if decoded[i] != in[i] {
+				t.Fatalf("Decoded[%d] != %v, got %v for mod %v", i, in[i], decoded[i], mod)
+			}
+		}
+	}
+}
+
+func TestDeltaColumns(t *testing.T) {
+	a := make([]int64, 100)
+	b := make([]int64, 100)
+	for i := 0; i < len(a); i++ {
+		b[i] = 1000000 + int64(i*i)
+		a[i] = b[i] + int64(i%5) - 2
+	}
+
+	deltas := make([]int64, len(a))
+	delta.DeltaColumns(deltas, a, b)
+	for i, v := range deltas {
+		if v < -2 || v > 2 {
+			t.Fatalf("Delta[%d] out of range: got %v", i, v)
+		}
+	}
+
+	decoded := make([]int64, len(a))
+	delta.InverseDeltaColumns(decoded, deltas, b)
+	for i := 0; i < len(a); i++ {
+		if decoded[i] != a[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, a[i], decoded[i])
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package delta
+
+import (
+	"encoding/binary"
+	"sort"
+
+	"github.com/jwilder/encoding/bitops"
+	"github.com/jwilder/encoding/simple8b"
+)
+
+// CheckpointInterval is the number of values between the absolute checkpoints
+// produced by EncodeCheckpointed.
+const CheckpointInterval = 128
+
+// EncodeCheckpointed delta encodes the sorted values in src, zigzag encodes the
+// deltas and packs them with simple8b.  It also returns the absolute value at
+// every CheckpointInterval'th index, which SearchDelta uses to locate a value
+// without decoding the whole buffer.  The first value is only stored in the
+// checkpoints so a large first value, such as a nanosecond timestamp, does not
+// need to fit in simple8b.
+func EncodeCheckpointed(src []int64) ([]byte, []int64, error) {
+	enc := simple8b.NewEncoder()
+	checkpoints := make([]int64, 0, (len(src)+CheckpointInterval-1)/CheckpointInterval)
+
+	for i, v := range src {
+		if i%CheckpointInterval == 0 {
+			checkpoints = append(checkpoints, v)
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		if err := enc.Write(bitops.ZigZagEncode64(v - src[i-1])); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	return b, checkpoints, nil
+}
+
+// SearchDelta returns the index of the first occurrence of target in a buffer
+// produced by EncodeCheckpointed, or -1 if target is not present.  The
+// checkpoints narrow the search to a single interval so only the values between
+// two checkpoints are decoded.
+func SearchDelta(b []byte, checkpoints []int64, target int64) (index int, err error) {
+	if len(checkpoints) == 0 {
+		return -1, nil
+	}
+
+	// Start from the checkpoint before the first one that is not less than the
+	// target since duplicates of the target may precede that checkpoint.
+	k := sort.Search(len(checkpoints), func(i int) bool { return checkpoints[i] >= target })
+	if k > 0 {
+		k--
+	}
+
+	start := k * CheckpointInterval
+	v := checkpoints[k]
+	if v == target {
+		return start, nil
+	} else if v > target {
+		return -1, nil
+	}
+
+	// The delta for the value at index i is stored at position i-1 since the
+	// first value is not packed, so the values after the checkpoint start at
+	// position start.
+	n, err := simple8b.CountBytes(b)
+	if err != nil {
+		return -1, err
+	} else if start >= n {
+		return -1, nil
+	}
+
+	word, offset, err := simple8b.WordIndexOf(b, start)
+	if err != nil {
+		return -1, err
+	}
+
+	var buf [240]uint64
+	b = b[word*8:]
+	skip := offset
+	index = start
+	for len(b) >= 8 {
+		n, err := simple8b.Decode(&buf, binary.BigEndian.Uint64(b[:8]))
+		if err != nil {
+			return -1, err
+		}
+		b = b[8:]
+
+		for _, d := range buf[skip:n] {
+			index++
+			v += bitops.ZigZagDecode64(d)
+			if v == target {
+				return index, nil
+			} else if v > target {
+				return -1, nil
+			}
+		}
+		skip = 0
+	}
+	return -1, nil
+}
@@ -0,0 +1,85 @@
+package delta_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/delta"
+)
+
+func TestSearchDelta(t *testing.T) {
+	in := make([]int64, 1000)
+	in[0] = -500
+	for i := 1; i < len(in); i++ {
+		// Even numbers only, with some repeated values
+		in[i] = in[i-1] + int64(2*(i%3))
+	}
+
+	b, checkpoints, err := delta.EncodeCheckpointed(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := (len(in)+delta.CheckpointInterval-1)/delta.CheckpointInterval, len(checkpoints); got != exp {
+		t.Fatalf("Checkpoint len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for _, i := range []int{0, 1, 127, 128, 129, 384, 500, 999} {
+		exp := i
+		for exp > 0 && in[exp-1] == in[i] {
+			exp--
+		}
+
+		got, err := delta.SearchDelta(b, checkpoints, in[i])
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != exp {
+			t.Fatalf("SearchDelta(%v) mismatch: exp %v, got %v", in[i], exp, got)
+		}
+	}
+
+	for _, target := range []int64{in[0] - 1, in[10] + 1, in[len(in)-1] + 2} {
+		got, err := delta.SearchDelta(b, checkpoints, target)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != -1 {
+			t.Fatalf("SearchDelta(%v) mismatch: exp %v, got %v", target, -1, got)
+		}
+	}
+}
+
+func TestSearchDelta_Timestamps(t *testing.T) {
+	// Nanosecond timestamps do not fit in simple8b so the first value must
+	// only be stored in the checkpoints.
+	in := make([]int64, 3*delta.CheckpointInterval+1)
+	in[0] = 1600000000000000000
+	for i := 1; i < len(in); i++ {
+		in[i] = in[i-1] + 1000000000 + int64(i%7)
+	}
+
+	b, checkpoints, err := delta.EncodeCheckpointed(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	for _, i := range []int{0, 1, 127, 128, 255, 256, len(in) - 1} {
+		got, err := delta.SearchDelta(b, checkpoints, in[i])
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != i {
+			t.Fatalf("SearchDelta(%v) mismatch: exp %v, got %v", in[i], i, got)
+		}
+	}
+
+	for _, target := range []int64{in[0] - 1, in[5] + 1, in[len(in)-1] + 1} {
+		got, err := delta.SearchDelta(b, checkpoints, target)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != -1 {
+			t.Fatalf("SearchDelta(%v) mismatch: exp %v, got %v", target, -1, got)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Package dict implements dictionary encoding of unsigned 64bit integers.  Each
+// distinct value is assigned a small id in order of first occurrence and the
+// ids are packed with simple8b.  Low cardinality columns with large values
+// compress far better as ids than as the values themselves.
+package dict
+
+import (
+	"fmt"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+// EncodeDict returns the distinct values of src, indexed by id, and the simple8b
+// encoded stream of ids that reproduces src.
+func EncodeDict(src []uint64) (dict []uint64, encoded []byte, err error) {
+	ids := make(map[uint64]uint64)
+	enc := simple8b.NewEncoder()
+
+	for _, v := range src {
+		id, ok := ids[v]
+		if !ok {
+			id = uint64(len(dict))
+			ids[v] = id
+			dict = append(dict, v)
+		}
+
+		if err := enc.Write(id); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	encoded, err = enc.Bytes()
+	if err != nil {
+		return nil, nil, err
+	}
+	return dict, encoded, nil
+}
+
+// DecodeDict returns the values of the encoded id stream looked up in dict.
+func DecodeDict(dict []uint64, encoded []byte) ([]uint64, error) {
+	n, err := simple8b.CountBytes(encoded)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]uint64, 0, n)
+	dec := simple8b.NewDecoder(encoded)
+	for dec.Next() {
+		id := dec.Read()
+		if id >= uint64(len(dict)) {
+			return nil, fmt.Errorf("invalid dictionary id: %v", id)
+		}
+		values = append(values, dict[id])
+	}
+	return values, nil
+}
@@ -0,0 +1,69 @@
+package dict_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/dict"
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestEncodeDict(t *testing.T) {
+	values := make([]uint64, 10)
+	for i := 0; i < len(values); i++ {
+		values[i] = 1<<50 + uint64(i)*1000003
+	}
+
+	in := make([]uint64, 20000)
+	for i := 0; i < len(in); i++ {
+		in[i] = values[(i*7)%len(values)]
+	}
+
+	d, encoded, err := dict.EncodeDict(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(values), len(d); got != exp {
+		t.Fatalf("Dictionary len mismatch: exp %v, got %v", exp, got)
+	}
+
+	decoded, err := dict.DecodeDict(d, encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), len(decoded); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+
+	// Compare against packing the values directly
+	enc := simple8b.NewEncoder()
+	for _, v := range in {
+		enc.Write(v)
+	}
+	plain, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if size := len(encoded) + len(d)*8; size >= len(plain) {
+		t.Fatalf("Expected dictionary encoding to be smaller: got %v, plain %v", size, len(plain))
+	}
+}
+
+func TestDecodeDict_InvalidID(t *testing.T) {
+	_, encoded, err := dict.EncodeDict([]uint64{1, 2, 3})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := dict.DecodeDict([]uint64{1, 2}, encoded); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
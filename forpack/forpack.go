@@ -0,0 +1,28 @@
+// Package forpack implements frame-of-reference bit packing.  The minimum value
+// of a slice is subtracted from every value and the results are packed at the
+// smallest fixed bit width that holds them all.  For columns whose values share
+// a uniform width this can beat the fixed selector widths of simple8b.
+package forpack
+
+import "github.com/jwilder/encoding/bitops"
+
+// ForBitPack returns the minimum value of src, the bit width required to store
+// every value less the minimum, and the packed values.
+func ForBitPack(src []uint64) (min uint64, bits int, packed []byte) {
+	min, max, _, _ := bitops.MinMax(src)
+	bits = bitops.BitLen(max - min)
+
+	values := make([]uint64, len(src))
+	for i, v := range src {
+		values[i] = v - min
+	}
+	return min, bits, bitops.BitPack(values, bits)
+}
+
+// ForBitUnpack reverses ForBitPack, writing len(dst) values to dst.
+func ForBitUnpack(dst []uint64, min uint64, bits int, packed []byte) {
+	bitops.BitUnpack(dst, packed, bits)
+	for i := range dst {
+		dst[i] += min
+	}
+}
@@ -0,0 +1,65 @@
+package forpack_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/forpack"
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestForBitPack(t *testing.T) {
+	// Values spread over a 9 bit range above a large base
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = 1<<20 + uint64(i*37)%512
+	}
+
+	min, bits, packed := forpack.ForBitPack(in)
+	if exp, got := uint64(1<<20), min; got != exp {
+		t.Fatalf("Min mismatch: exp %v, got %v", exp, got)
+	}
+	if exp, got := 9, bits; got != exp {
+		t.Fatalf("Bits mismatch: exp %v, got %v", exp, got)
+	}
+
+	out := make([]uint64, len(in))
+	forpack.ForBitUnpack(out, min, bits, packed)
+	for i := 0; i < len(in); i++ {
+		if out[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], out[i])
+		}
+	}
+
+	// Give simple8b the same frame of reference
+	enc := simple8b.NewEncoder()
+	for _, v := range in {
+		enc.Write(v - min)
+	}
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// min and bits are stored alongside the packed values, and min alongside
+	// the simple8b words
+	if size, other := len(packed)+9, len(b)+8; size >= other {
+		t.Fatalf("Expected smaller encoding than simple8b: got %v, simple8b %v", size, other)
+	}
+}
+
+func TestForBitPack_Equal(t *testing.T) {
+	in := []uint64{42, 42, 42}
+
+	min, bits, packed := forpack.ForBitPack(in)
+	if min != 42 || bits != 0 || len(packed) != 0 {
+		t.Fatalf("ForBitPack mismatch: got %v, %v, %v", min, bits, len(packed))
+	}
+
+	out := make([]uint64, len(in))
+	forpack.ForBitUnpack(out, min, bits, packed)
+	for i := 0; i < len(in); i++ {
+		if out[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], out[i])
+		}
+	}
+}
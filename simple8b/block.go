@@ -0,0 +1,39 @@
+package simple8b
+
+import (
+	"fmt"
+)
+
+// Block is a slice of unsigned 64bit integers that can be marshaled to and from
+// the compressed simple8b byte form.
+type Block []uint64
+
+// MarshalBinary returns the values in the block as a compressed byte slice.
+func (b Block) MarshalBinary() ([]byte, error) {
+	enc := NewEncoder()
+	for _, v := range b {
+		if err := enc.Write(v); err != nil {
+			return nil, err
+		}
+	}
+	return enc.Bytes()
+}
+
+// UnmarshalBinary replaces the values in the block with those decoded from data.
+func (b *Block) UnmarshalBinary(data []byte) error {
+	n, err := CountBytes(data)
+	if err != nil {
+		return err
+	}
+
+	values := make([]uint64, 0, n)
+	err = EachWord(data, func(vals []uint64) {
+		values = append(values, vals...)
+	})
+	if err != nil {
+		return fmt.Errorf("unable to decode block: %v", err)
+	}
+
+	*b = values
+	return nil
+}
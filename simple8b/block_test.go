@@ -0,0 +1,41 @@
+package simple8b_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestBlock_MarshalBinary(t *testing.T) {
+	in := make(simple8b.Block, 500)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i * i)
+	}
+
+	b, err := in.MarshalBinary()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var out simple8b.Block
+	if err := out.UnmarshalBinary(b); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), len(out); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if out[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], out[i])
+		}
+	}
+}
+
+func TestBlock_UnmarshalBinary_Invalid(t *testing.T) {
+	var out simple8b.Block
+	if err := out.UnmarshalBinary([]byte{0, 1, 2}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,88 @@
+package simple8b
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+)
+
+// ErrChecksumMismatch is returned when the CRC32 footer of a checksummed buffer
+// does not match its encoded words.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+// EncodeAllChecksummed returns the encoded bytes of src followed by a 4 byte big
+// endian CRC32 (IEEE) of those bytes.  Unlike EncodeAll, src is not modified.
+func EncodeAllChecksummed(src []uint64) ([]byte, error) {
+	enc := NewEncoder()
+	for _, v := range src {
+		if err := enc.Write(v); err != nil {
+			return nil, err
+		}
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	var sum [4]byte
+	binary.BigEndian.PutUint32(sum[:], crc32.ChecksumIEEE(b))
+	return append(b, sum[:]...), nil
+}
+
+// DecodeAllChecksummed verifies the CRC32 footer of b and writes the decoded
+// values to dst.  It returns the number of values written, ErrChecksumMismatch
+// if the footer does not match, or another error if b is malformed.
+func DecodeAllChecksummed(dst []uint64, b []byte) (int, error) {
+	if len(b) < 4 {
+		return 0, fmt.Errorf("missing checksum: %v bytes", len(b))
+	}
+
+	words, sum := b[:len(b)-4], binary.BigEndian.Uint32(b[len(b)-4:])
+	if crc32.ChecksumIEEE(words) != sum {
+		return 0, ErrChecksumMismatch
+	}
+
+	if len(words)%8 != 0 {
+		return 0, fmt.Errorf("invalid slice len remaining: %v", len(words)%8)
+	}
+
+	var (
+		j      int
+		dstErr error
+	)
+	err := walkWords(words, func(vals []uint64) bool {
+		if j+len(vals) > len(dst) {
+			dstErr = fmt.Errorf("dst too small: need at least %v", j+len(vals))
+			return false
+		}
+		j += copy(dst[j:], vals)
+		return true
+	})
+	if err != nil {
+		return 0, err
+	} else if dstErr != nil {
+		return 0, dstErr
+	}
+	return j, nil
+}
+
+// Digest returns a 64 bit FNV-1a hash of the values encoded in the byte slice.
+// The hash covers the decoded values rather than the encoded bytes, so columns
+// holding the same values compare equal however they were packed.
+func Digest(b []byte) (uint64, error) {
+	h := fnv.New64a()
+	var tmp [8]byte
+	err := EachWord(b, func(vals []uint64) {
+		for _, v := range vals {
+			binary.BigEndian.PutUint64(tmp[:], v)
+			h.Write(tmp[:])
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return h.Sum64(), nil
+}
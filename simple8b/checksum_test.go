@@ -0,0 +1,89 @@
+package simple8b_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestEncodeAllChecksummed(t *testing.T) {
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i * 3)
+	}
+
+	b, err := simple8b.EncodeAllChecksummed(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dst := make([]uint64, len(in))
+	n, err := simple8b.DecodeAllChecksummed(dst, b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), n; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if dst[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dst[i])
+		}
+	}
+}
+
+func TestDecodeAllChecksummed_Mismatch(t *testing.T) {
+	in := make([]uint64, 100)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i)
+	}
+
+	b, err := simple8b.EncodeAllChecksummed(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b[10] ^= 0xff
+
+	dst := make([]uint64, len(in))
+	if _, err := simple8b.DecodeAllChecksummed(dst, b); err != simple8b.ErrChecksumMismatch {
+		t.Fatalf("Error mismatch: exp %v, got %v", simple8b.ErrChecksumMismatch, err)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 64)
+	}
+
+	a, err := simple8b.Digest(encodeValues(t, in))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	b, err := simple8b.Digest(encodeValues(t, in))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if a != b {
+		t.Fatalf("Digest mismatch: exp %v, got %v", a, b)
+	}
+
+	in[500]++
+	c, err := simple8b.Digest(encodeValues(t, in))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if a == c {
+		t.Fatalf("Expected digest to change, got %v", c)
+	}
+
+	if _, err := simple8b.Digest([]byte{1, 2, 3}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
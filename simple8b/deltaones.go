@@ -0,0 +1,142 @@
+package simple8b
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// maxOnesExceptions is the largest fraction, as 1/n, of deltas that may differ
+// from 1 for EncodeDeltaOnes to store them as exceptions.
+const maxOnesExceptions = 16
+
+// EncodeDeltaOnes delta encodes src, which is expected to be a mostly
+// sequential id column where nearly every value is 1 more than the previous.
+// Deltas other than 1 are replaced with 1 and stored separately as exceptions
+// so the packed deltas stay in unbroken runs of ones, which selectors 0 and 1
+// store in a single word per 240 or 120 values.  If too many deltas differ from
+// 1, the deltas are packed as is.  Deltas use wrapping arithmetic and must fit in
+// MaxValue.
+//
+// The output holds the value count, the first value and the packed delta length
+// as uvarints, then the packed deltas followed by the packed exceptions as
+// pairs of index gap and delta.
+func EncodeDeltaOnes(src []uint64) ([]byte, error) {
+	var hdr [3 * binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(hdr[:], uint64(len(src)))
+	if len(src) == 0 {
+		return append([]byte(nil), hdr[:n]...), nil
+	}
+	n += binary.PutUvarint(hdr[n:], src[0])
+
+	var exceptions int
+	for i := 1; i < len(src); i++ {
+		if src[i]-src[i-1] != 1 {
+			exceptions++
+		}
+	}
+	sparse := exceptions*maxOnesExceptions <= len(src)
+
+	deltas, excs := NewEncoder(), NewEncoder()
+	last := 0
+	for i := 1; i < len(src); i++ {
+		d := src[i] - src[i-1]
+		if sparse && d != 1 {
+			if err := excs.Write(uint64(i - last)); err != nil {
+				return nil, err
+			}
+			if err := excs.Write(d); err != nil {
+				return nil, err
+			}
+			last = i
+			d = 1
+		}
+
+		if err := deltas.Write(d); err != nil {
+			return nil, err
+		}
+	}
+
+	db, err := deltas.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	eb, err := excs.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	n += binary.PutUvarint(hdr[n:], uint64(len(db)))
+	b := make([]byte, 0, n+len(db)+len(eb))
+	b = append(b, hdr[:n]...)
+	b = append(b, db...)
+	return append(b, eb...), nil
+}
+
+// DecodeDeltaOnes returns the values encoded by EncodeDeltaOnes.
+func DecodeDeltaOnes(b []byte) ([]uint64, error) {
+	count, b, err := readUvarint(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if count == 0 {
+		return nil, nil
+	}
+
+	first, b, err := readUvarint(b)
+	if err != nil {
+		return nil, err
+	}
+
+	size, b, err := readUvarint(b)
+	if err != nil {
+		return nil, err
+	}
+
+	if size > uint64(len(b)) {
+		return nil, fmt.Errorf("invalid packed length: %v", size)
+	}
+
+	n, err := CountBytes(b[:size])
+	if err != nil {
+		return nil, err
+	}
+
+	if uint64(n)+1 != count {
+		return nil, fmt.Errorf("value count mismatch: exp %v, got %v", count, n+1)
+	}
+
+	deltas := make([]uint64, 0, count)
+	deltas = append(deltas, first)
+	if err := EachWord(b[:size], func(vals []uint64) {
+		deltas = append(deltas, vals...)
+	}); err != nil {
+		return nil, err
+	}
+
+	var excs []uint64
+	if err := EachWord(b[size:], func(vals []uint64) {
+		excs = append(excs, vals...)
+	}); err != nil {
+		return nil, err
+	}
+
+	if len(excs)%2 != 0 {
+		return nil, fmt.Errorf("invalid exception count: %v", len(excs))
+	}
+
+	idx := uint64(0)
+	for i := 0; i < len(excs); i += 2 {
+		idx += excs[i]
+		if idx == 0 || idx >= count {
+			return nil, fmt.Errorf("invalid exception index: %v", idx)
+		}
+		deltas[idx] = excs[i+1]
+	}
+
+	for i := 1; i < len(deltas); i++ {
+		deltas[i] += deltas[i-1]
+	}
+	return deltas, nil
+}
@@ -0,0 +1,93 @@
+package simple8b_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestEncodeDeltaOnes(t *testing.T) {
+	in := make([]uint64, 10000)
+	id := uint64(1 << 40)
+	for i := 0; i < len(in); i++ {
+		in[i] = id
+		id++
+
+		// A few gaps from deleted ids
+		if i%1500 == 0 {
+			id += uint64(i%7 + 2)
+		}
+	}
+
+	b, err := simple8b.EncodeDeltaOnes(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	deltas := make([]uint64, len(in))
+	for i := 1; i < len(in); i++ {
+		deltas[i] = in[i] - in[i-1]
+	}
+	if plain := len(encodeValues(t, deltas)); len(b) >= plain {
+		t.Fatalf("Expected smaller encoding than plain deltas: got %v, plain %v", len(b), plain)
+	}
+
+	out, err := simple8b.DecodeDeltaOnes(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), len(out); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if out[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], out[i])
+		}
+	}
+}
+
+func TestEncodeDeltaOnes_Dense(t *testing.T) {
+	// Too many gaps to store as exceptions
+	in := make([]uint64, 1000)
+	for i := 1; i < len(in); i++ {
+		in[i] = in[i-1] + uint64(i%3)
+	}
+
+	b, err := simple8b.EncodeDeltaOnes(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out, err := simple8b.DecodeDeltaOnes(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), len(out); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if out[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], out[i])
+		}
+	}
+}
+
+func TestEncodeDeltaOnes_Empty(t *testing.T) {
+	b, err := simple8b.EncodeDeltaOnes(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	out, err := simple8b.DecodeDeltaOnes(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(out) != 0 {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", 0, len(out))
+	}
+}
@@ -0,0 +1,77 @@
+package simple8b
+
+import (
+	"math"
+	"math/bits"
+)
+
+// distinctPrecision is the number of hash bits used to select a register in
+// DistinctEstimate.  2^12 registers give a standard error of about 1.6%.
+const distinctPrecision = 12
+
+// DistinctCount returns the exact number of distinct values encoded in the
+// byte slice.  Memory use grows with the number of distinct values, use
+// DistinctEstimate for large inputs.
+func DistinctCount(b []byte) (int, error) {
+	seen := make(map[uint64]struct{})
+	err := EachWord(b, func(vals []uint64) {
+		for _, v := range vals {
+			seen[v] = struct{}{}
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(seen), nil
+}
+
+// DistinctEstimate returns an estimate of the number of distinct values
+// encoded in the byte slice using a HyperLogLog sketch of fixed size.
+func DistinctEstimate(b []byte) (int, error) {
+	const m = 1 << distinctPrecision
+	var registers [m]uint8
+
+	err := EachWord(b, func(vals []uint64) {
+		for _, v := range vals {
+			h := mix64(v)
+			idx := h >> (64 - distinctPrecision)
+			rank := uint8(bits.LeadingZeros64(h<<distinctPrecision|1<<(distinctPrecision-1)) + 1)
+			if rank > registers[idx] {
+				registers[idx] = rank
+			}
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var (
+		sum   float64
+		zeros int
+	)
+	for _, r := range registers {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+
+	alpha := 0.7213 / (1 + 1.079/m)
+	est := alpha * m * m / sum
+
+	// Linear counting is more accurate while many registers are still empty
+	if est <= 2.5*m && zeros > 0 {
+		est = m * math.Log(float64(m)/float64(zeros))
+	}
+	return int(est + 0.5), nil
+}
+
+// mix64 scrambles the bits of v so that sequential values hash uniformly.
+func mix64(v uint64) uint64 {
+	v ^= v >> 33
+	v *= 0xff51afd7ed558ccd
+	v ^= v >> 33
+	v *= 0xc4ceb9fe1a85ec53
+	v ^= v >> 33
+	return v
+}
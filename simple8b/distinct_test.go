@@ -0,0 +1,64 @@
+package simple8b_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestDistinctCount(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 20000; i++ {
+		enc.Write(uint64(i % 5000))
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	got, err := simple8b.DistinctCount(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp := 5000; got != exp {
+		t.Fatalf("DistinctCount mismatch: exp %v, got %v", exp, got)
+	}
+
+	est, err := simple8b.DistinctEstimate(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if est < 4750 || est > 5250 {
+		t.Fatalf("DistinctEstimate out of range: exp ~%v, got %v", 5000, est)
+	}
+}
+
+func TestDistinctEstimate_Small(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 1000; i++ {
+		enc.Write(uint64(i % 10))
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	est, err := simple8b.DistinctEstimate(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp := 10; est != exp {
+		t.Fatalf("DistinctEstimate mismatch: exp %v, got %v", exp, est)
+	}
+}
+
+func TestDistinctCount_Invalid(t *testing.T) {
+	if _, err := simple8b.DistinctCount([]byte{0, 1, 2}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
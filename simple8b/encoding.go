@@ -23,6 +23,7 @@ package simple8b
 import (
 	"encoding/binary"
 	"fmt"
+	"io"
 	"unsafe"
 )
 
@@ -45,34 +46,103 @@ type Encoder struct {
 	// current bytes written and flushed
 	bytes []byte
 	b     []byte
+
+	// total number of values written, flushed or not
+	count int
+
+	// whether Bytes prepends a word holding the total value count
+	lengthPrefix bool
+
+	// optional custom framing for flushed words
+	serialize WordSerializer
+
+	// flushed bytes needed before a chunk is ready, 0 if disabled
+	flushThreshold int
+
+	// number of values handed back by drain
+	drained int
+}
+
+// MaxSerializedWordLen is the number of bytes available to a WordSerializer
+// for a single word.
+const MaxSerializedWordLen = 16
+
+// WordSerializer writes word into dst and returns the number of bytes written.
+// dst is at least MaxSerializedWordLen bytes long.
+type WordSerializer func(dst []byte, word uint64) int
+
+// WordDeserializer reads a single word from the front of src and returns it
+// along with the number of bytes consumed.  It returns 0 bytes consumed if src
+// does not hold a complete word.
+type WordDeserializer func(src []byte) (word uint64, n int)
+
+// EncoderOption configures optional behavior of an Encoder.
+type EncoderOption func(e *Encoder)
+
+// WithLengthPrefix returns an option that makes Bytes prepend an 8 byte big
+// endian word holding the number of encoded values it returns.  Use
+// NewLengthPrefixedDecoder to read the result.
+func WithLengthPrefix() EncoderOption {
+	return func(e *Encoder) {
+		e.lengthPrefix = true
+	}
 }
 
 // NewEncoder returns an Encoder able to convert uint64s to compressed byte slices
-func NewEncoder() *Encoder {
-	return &Encoder{
+func NewEncoder(opts ...EncoderOption) *Encoder {
+	e := &Encoder{
 		buf:   make([]uint64, 240),
 		b:     make([]byte, 8),
 		bytes: make([]byte, 128),
 	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.bp = e.headerLen()
+	return e
+}
+
+// headerLen returns the number of bytes reserved at the front of the output
+func (e *Encoder) headerLen() int {
+	if e.lengthPrefix {
+		return 8
+	}
+	return 0
 }
 
 func (e *Encoder) SetValues(v []uint64) {
 	e.buf = v
 	e.t = len(v)
 	e.h = 0
-	e.bytes = e.bytes[:0]
+	e.bytes = e.bytes[:e.headerLen()]
+	e.bp = len(e.bytes)
+	e.count = len(v)
+	e.drained = 0
 }
 
 func (e *Encoder) Reset() {
 	e.t = 0
 	e.h = 0
-	e.bp = 0
+	e.bp = e.headerLen()
+	e.count = 0
+	e.drained = 0
 
 	e.buf = e.buf[:240]
-	e.b = e.b[:8]
+	e.b = e.b[:cap(e.b)]
 	e.bytes = e.bytes[:128]
 }
 
+// SetWordSerializer replaces the default big endian framing of each encoded
+// word with fn.  Passing nil restores the default.  Output written with a
+// custom serializer must be read by a Decoder using a matching
+// WordDeserializer.
+func (e *Encoder) SetWordSerializer(fn WordSerializer) {
+	e.serialize = fn
+	if fn != nil && len(e.b) < MaxSerializedWordLen {
+		e.b = make([]byte, MaxSerializedWordLen)
+	}
+}
+
 func (e *Encoder) Write(v uint64) error {
 	if e.t >= len(e.buf) {
 		if err := e.flush(); err != nil {
@@ -89,9 +159,83 @@ func (e *Encoder) Write(v uint64) error {
 	}
 	e.buf[e.t] = v
 	e.t += 1
+	e.count += 1
 	return nil
 }
 
+// SetFlushThreshold enables draining encoded output in chunks while writing.
+// Once at least n bytes of flushed words are pending, ChunkReady returns true
+// and TakeChunk hands them back.  A value of 0 disables chunking.
+func (e *Encoder) SetFlushThreshold(n int) {
+	e.flushThreshold = n
+}
+
+// ChunkReady returns true if the flushed words pending in the encoder have
+// reached the flush threshold.
+func (e *Encoder) ChunkReady() bool {
+	return e.flushThreshold > 0 && e.bp-e.headerLen() >= e.flushThreshold
+}
+
+// TakeChunk returns a copy of the flushed words pending in the encoder and
+// removes them from the output, or nil if no chunk is ready.  Values still
+// buffered are returned by later chunks or by Bytes.  Concatenating the chunks
+// and the final Bytes output reproduces the full encoding.  If WithLengthPrefix
+// is used, each chunk and the final Bytes output instead carry their own prefix
+// counting only their values, so each must be read with its own
+// NewLengthPrefixedDecoder.
+func (e *Encoder) TakeChunk() []byte {
+	if !e.ChunkReady() {
+		return nil
+	}
+
+	hdr := e.headerLen()
+	n, b := e.drain()
+	chunk := make([]byte, hdr+len(b))
+	if e.lengthPrefix {
+		binary.BigEndian.PutUint64(chunk, uint64(n))
+	}
+	copy(chunk[hdr:], b)
+	return chunk
+}
+
+// drain returns the flushed words pending in the encoder, along with the number
+// of values they hold, and removes them from the output.  The returned slice
+// aliases the encoder's storage and is only valid until the next Write.
+func (e *Encoder) drain() (int, []byte) {
+	// Values still buffered have not been flushed into words yet
+	n := e.count - e.drained - (e.t - e.h)
+	e.drained += n
+
+	hdr := e.headerLen()
+	b := e.bytes[hdr:e.bp]
+	e.bp = hdr
+	return n, b
+}
+
+// ValueCount returns the total number of values written to the encoder,
+// including those that have not been flushed yet.
+func (e *Encoder) ValueCount() int {
+	return e.count
+}
+
+// Grow pre-sizes the encoder's output buffer so that at least nValues more
+// values can be encoded without reallocating.  It assumes the worst case of one
+// value per encoded word.
+func (e *Encoder) Grow(nValues int) {
+	if nValues <= 0 {
+		return
+	}
+
+	need := e.bp + nValues*8
+	if need <= len(e.bytes) {
+		return
+	}
+
+	b := make([]byte, need)
+	copy(b, e.bytes[:e.bp])
+	e.bytes = b
+}
+
 func (e *Encoder) flush() error {
 	if e.t == 0 {
 		return nil
@@ -102,13 +246,18 @@ func (e *Encoder) flush() error {
 	if err != nil {
 		return err
 	}
-	binary.BigEndian.PutUint64(e.b, encoded)
-	if e.bp+8 > len(e.bytes) {
-		e.bytes = append(e.bytes, e.b...)
+	wn := 8
+	if e.serialize != nil {
+		wn = e.serialize(e.b, encoded)
+	} else {
+		binary.BigEndian.PutUint64(e.b, encoded)
+	}
+	if e.bp+wn > len(e.bytes) {
+		e.bytes = append(e.bytes[:e.bp], e.b[:wn]...)
 		e.bp = len(e.bytes)
 	} else {
-		copy(e.bytes[e.bp:e.bp+8], e.b)
-		e.bp += 8
+		copy(e.bytes[e.bp:e.bp+wn], e.b[:wn])
+		e.bp += wn
 	}
 
 	// Move the head forward since we encoded those values
@@ -123,6 +272,9 @@ func (e *Encoder) flush() error {
 	return nil
 }
 
+// Bytes flushes any buffered values and returns the encoded bytes.  The returned
+// slice aliases the encoder's internal storage and is only valid until the next
+// call to Write, SetValues or Reset.  Use BytesCopy to retain the result.
 func (e *Encoder) Bytes() ([]byte, error) {
 	for e.t > 0 {
 		if err := e.flush(); err != nil {
@@ -130,15 +282,49 @@ func (e *Encoder) Bytes() ([]byte, error) {
 		}
 	}
 
+	if e.lengthPrefix {
+		binary.BigEndian.PutUint64(e.bytes[:8], uint64(e.count-e.drained))
+	}
 	return e.bytes[:e.bp], nil
 }
 
+// BytesWithCount is like Bytes but also returns the number of values encoded,
+// saving the caller a CountBytes pass.  Values already returned by TakeChunk are
+// not counted.
+func (e *Encoder) BytesWithCount() ([]byte, int, error) {
+	b, err := e.Bytes()
+	if err != nil {
+		return nil, 0, err
+	}
+	return b, e.count - e.drained, nil
+}
+
+// BytesCopy is like Bytes but returns a copy of the encoded bytes that is safe
+// to retain after the encoder is reused.
+func (e *Encoder) BytesCopy() ([]byte, error) {
+	b, err := e.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(nil), b...), nil
+}
+
 // Decoder converts a compressed byte slice to a stream of unsigned 64bit integers.
 type Decoder struct {
 	bytes []byte
 	buf   [240]uint64
 	i     int
 	n     int
+
+	// number of values consumed by Next
+	pos int
+
+	// whether words with unknown markers are skipped and how many were
+	lenient bool
+	skipped int
+
+	// optional custom framing for encoded words
+	deserialize WordDeserializer
 }
 
 // NewDecoder returns a Decoder from a byte slice
@@ -148,6 +334,17 @@ func NewDecoder(b []byte) *Decoder {
 	}
 }
 
+// NewLengthPrefixedDecoder returns a Decoder for a byte slice produced by an
+// Encoder created with WithLengthPrefix, along with the number of encoded values
+// stored in the prefix.
+func NewLengthPrefixedDecoder(b []byte) (*Decoder, int, error) {
+	if len(b) < 8 {
+		return nil, 0, fmt.Errorf("missing length prefix: %v bytes", len(b))
+	}
+	n := binary.BigEndian.Uint64(b[:8])
+	return NewDecoder(b[8:]), int(n), nil
+}
+
 // Next returns true if there are remaining values to be read.  Successive
 // calls to Next advance the current element pointer.
 func (d *Decoder) Next() bool {
@@ -157,13 +354,50 @@ func (d *Decoder) Next() bool {
 		d.read()
 	}
 
-	return len(d.bytes) >= 8 || (d.i >= 0 && d.i < d.n)
+	if d.i >= 0 && d.i < d.n {
+		d.pos += 1
+		return true
+	}
+	return false
 }
 
 func (d *Decoder) SetBytes(b []byte) {
 	d.bytes = b
 	d.i = 0
 	d.n = 0
+	d.pos = 0
+	d.skipped = 0
+}
+
+// SetLenientMode controls whether the decoder skips words that carry data in
+// bits that the current format leaves unused, instead of decoding them.  This
+// allows reading buffers from newer writers that use those bits.  The words
+// treated as skippable are:
+//
+//   - selector 0 and 1 words with any of the low 60 bits set, since the run
+//     selectors encode no value bits.
+//   - selector 8 and 9 words with any of bits 56-59 set, since 8x7 and 7x8 bit
+//     values only fill the low 56 bits.
+func (d *Decoder) SetLenientMode(enabled bool) {
+	d.lenient = enabled
+}
+
+// SetWordDeserializer sets the function used to read each encoded word,
+// matching an Encoder configured with SetWordSerializer.  Passing nil
+// restores the default big endian framing.
+func (d *Decoder) SetWordDeserializer(fn WordDeserializer) {
+	d.deserialize = fn
+}
+
+// SkippedWords returns the number of words skipped while in lenient mode.
+func (d *Decoder) SkippedWords() int {
+	return d.skipped
+}
+
+// Position returns the number of values consumed by calls to Next.  The value
+// returned by Read is at logical index Position()-1.
+func (d *Decoder) Position() int {
+	return d.pos
 }
 
 // Read returns the current value.  Successive calls to Read return the same
@@ -173,15 +407,66 @@ func (d *Decoder) Read() uint64 {
 	return v
 }
 
-func (d *Decoder) read() {
-	if len(d.bytes) < 8 {
-		return
+// ReadInto copies up to len(dst) of the values following the current position
+// into dst and returns how many were copied.  Calls may be mixed with Next and
+// Read, which continue after the last value copied.  io.EOF is returned once no
+// values remain.
+func (d *Decoder) ReadInto(dst []uint64) (int, error) {
+	j := 0
+	for j < len(dst) {
+		if d.i+1 >= d.n {
+			if !d.read() {
+				break
+			}
+			d.i = -1
+		}
+		k := copy(dst[j:], d.buf[d.i+1:d.n])
+		d.i += k
+		j += k
+	}
+	d.pos += j
+
+	if j == 0 && len(dst) > 0 {
+		return 0, io.EOF
 	}
+	return j, nil
+}
 
-	v := binary.BigEndian.Uint64(d.bytes[:8])
-	d.bytes = d.bytes[8:]
+// read decodes the next word into buf and returns false if no words remain.
+func (d *Decoder) read() bool {
+	var v uint64
+	if d.deserialize != nil {
+		var n int
+		v, n = d.deserialize(d.bytes)
+		if n <= 0 {
+			return false
+		}
+		d.bytes = d.bytes[n:]
+	} else {
+		if len(d.bytes) < 8 {
+			return false
+		}
+		v = binary.BigEndian.Uint64(d.bytes[:8])
+		d.bytes = d.bytes[8:]
+	}
+	if d.lenient && hasUnknownMarker(v) {
+		d.skipped += 1
+		return d.read()
+	}
 	d.n, _ = Decode(&d.buf, v)
 	d.i = 0
+	return true
+}
+
+// hasUnknownMarker returns true if v has bits set that its selector does not use.
+func hasUnknownMarker(v uint64) bool {
+	switch v >> 60 {
+	case 0, 1:
+		return v&MaxValue != 0
+	case 8, 9:
+		return (v>>56)&0xf != 0
+	}
+	return false
 }
 
 type packing struct {
@@ -229,6 +514,20 @@ func CountBytes(b []byte) (int, error) {
 	return count, nil
 }
 
+// CanConcat returns true if the encoded byte slices a and b can be appended to
+// each other without re-encoding.  Encoded words are self-contained so this only
+// requires both slices to hold whole words.  If not, the error describes the
+// misaligned slice.
+func CanConcat(a, b []byte) (bool, error) {
+	if len(a)%8 != 0 {
+		return false, fmt.Errorf("invalid slice len remaining in a: %v", len(a)%8)
+	}
+	if len(b)%8 != 0 {
+		return false, fmt.Errorf("invalid slice len remaining in b: %v", len(b)%8)
+	}
+	return true, nil
+}
+
 // Count returns the number of integers encoded within an uint64
 func Count(v uint64) (int, error) {
 	sel := v >> 60
@@ -238,6 +537,146 @@ func Count(v uint64) (int, error) {
 	return selector[sel].n, nil
 }
 
+// EachWord decodes each word in the byte slice and calls fn with the values of
+// that word.  The slice passed to fn is reused between calls and must not be
+// retained.
+func EachWord(b []byte, fn func(vals []uint64)) error {
+	return walkWords(b, func(vals []uint64) bool {
+		fn(vals)
+		return true
+	})
+}
+
+// walkWords is like EachWord but stops without an error as soon as fn returns
+// false.
+func walkWords(b []byte, fn func(vals []uint64) bool) error {
+	var buf [240]uint64
+	for len(b) >= 8 {
+		v := binary.BigEndian.Uint64(b[:8])
+		b = b[8:]
+
+		n, err := Decode(&buf, v)
+		if err != nil {
+			return err
+		}
+
+		if !fn(buf[:n]) {
+			return nil
+		}
+	}
+
+	if len(b) > 0 {
+		return fmt.Errorf("invalid slice len remaining: %v", len(b))
+	}
+	return nil
+}
+
+// ValuesUpToByte returns the values held by the complete words within the first
+// maxBytes bytes of b, ignoring any trailing partial word.  This decodes the
+// loaded prefix of a buffer that is read progressively.
+func ValuesUpToByte(b []byte, maxBytes int) ([]uint64, error) {
+	if maxBytes < 0 {
+		return nil, fmt.Errorf("invalid byte offset: %v", maxBytes)
+	}
+
+	if maxBytes > len(b) {
+		maxBytes = len(b)
+	}
+	b = b[:maxBytes-maxBytes%8]
+
+	var values []uint64
+	if err := EachWord(b, func(vals []uint64) {
+		values = append(values, vals...)
+	}); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// WordIndexOf returns the index of the word in the byte slice that holds the
+// value at valueIndex, along with the value's offset within that word.
+func WordIndexOf(b []byte, valueIndex int) (wordIndex int, offsetWithinWord int, err error) {
+	if valueIndex < 0 {
+		return 0, 0, fmt.Errorf("invalid value index: %v", valueIndex)
+	}
+
+	var count int
+	for i := 0; i+8 <= len(b); i += 8 {
+		n, err := Count(binary.BigEndian.Uint64(b[i : i+8]))
+		if err != nil {
+			return 0, 0, err
+		}
+
+		if valueIndex < count+n {
+			return i / 8, valueIndex - count, nil
+		}
+		count += n
+	}
+	return 0, 0, fmt.Errorf("value index out of range: %v >= %v", valueIndex, count)
+}
+
+// OffsetTable returns the byte offset of each word in the byte slice along with
+// the index of the first value held by that word.  Together they form a seek
+// index: the value at index i lives in the last word whose value offset is <= i.
+func OffsetTable(b []byte) (byteOffsets, valueOffsets []int, err error) {
+	if len(b)%8 != 0 {
+		return nil, nil, fmt.Errorf("invalid slice len remaining: %v", len(b)%8)
+	}
+
+	byteOffsets = make([]int, 0, len(b)/8)
+	valueOffsets = make([]int, 0, len(b)/8)
+
+	var count int
+	for i := 0; i < len(b); i += 8 {
+		n, err := Count(binary.BigEndian.Uint64(b[i : i+8]))
+		if err != nil {
+			return nil, nil, err
+		}
+		byteOffsets = append(byteOffsets, i)
+		valueOffsets = append(valueOffsets, count)
+		count += n
+	}
+	return byteOffsets, valueOffsets, nil
+}
+
+// WordsNeeded returns the number of uint64 words required to encode count values
+// that can each be stored using bits bits.  It returns -1 if bits exceeds the
+// 60 bits available in a word.
+func WordsNeeded(count, bits int) int {
+	if bits < 0 || bits > 60 {
+		return -1
+	}
+	if count <= 0 {
+		return 0
+	}
+
+	// Selector 0,1 only encode runs of 1's so start with the densest selector
+	// that can hold any value of the given width.
+	for sel := 2; sel < 16; sel++ {
+		if selector[sel].bit >= bits {
+			n := selector[sel].n
+			return (count + n - 1) / n
+		}
+	}
+	return -1
+}
+
+// EstimateBytes returns the number of bytes src would occupy once encoded.  The
+// words are counted rather than serialized and src is not modified.  Values too
+// large to encode are counted as a word each so the estimate is always defined.
+func EstimateBytes(src []uint64) int {
+	var words int
+	for len(src) > 0 {
+		_, n, err := Encode(src)
+		if err != nil {
+			n = 1
+		}
+		src = src[n:]
+		words++
+	}
+	return words * 8
+}
+
 func ForEach(b []byte, fn func(v uint64) bool) error {
 	for len(b) >= 8 {
 		v := binary.BigEndian.Uint64(b[:8])
@@ -300,6 +739,134 @@ func CountBytesBetween(b []byte, min, max uint64) (int, error) {
 	return count, nil
 }
 
+// Sum returns the sum of the values encoded in the byte slice without decoding
+// it into a separate slice.  The sum wraps around modulo 2^64 on overflow.
+func Sum(b []byte) (uint64, error) {
+	var sum uint64
+	err := EachWord(b, func(vals []uint64) {
+		for _, v := range vals {
+			sum += v
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return sum, nil
+}
+
+// MinMax returns the smallest and largest values encoded in the byte slice
+// without decoding it into a separate slice.  An empty slice returns 0 for both.
+func MinMax(b []byte) (min, max uint64, err error) {
+	first := true
+	err = EachWord(b, func(vals []uint64) {
+		for _, val := range vals {
+			if first {
+				min, max = val, val
+				first = false
+			} else if val < min {
+				min = val
+			} else if val > max {
+				max = val
+			}
+		}
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return min, max, nil
+}
+
+// DecodeGrouped returns the values encoded in the byte slice grouped by the
+// word they were decoded from.  Element i holds the values of word i.
+func DecodeGrouped(b []byte) ([][]uint64, error) {
+	groups := make([][]uint64, 0, len(b)/8)
+	err := EachWord(b, func(vals []uint64) {
+		groups = append(groups, append([]uint64(nil), vals...))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return groups, nil
+}
+
+// IsAllOnes returns true if every word in the byte slice uses one of the run
+// selectors (0 or 1) and therefore only encodes values of 1.  An empty slice
+// returns false.
+func IsAllOnes(b []byte) (bool, error) {
+	if len(b) == 0 {
+		return false, nil
+	}
+
+	for len(b) >= 8 {
+		v := binary.BigEndian.Uint64(b[:8])
+		b = b[8:]
+
+		if sel := v >> 60; sel > 1 {
+			return false, nil
+		}
+	}
+
+	if len(b) > 0 {
+		return false, fmt.Errorf("invalid slice len remaining: %v", len(b))
+	}
+	return true, nil
+}
+
+// IsSortedBytes returns true if the values encoded in the byte slice are in
+// non-decreasing order.
+func IsSortedBytes(b []byte) (bool, error) {
+	var (
+		prev   uint64
+		sorted = true
+	)
+	err := walkWords(b, func(vals []uint64) bool {
+		for _, val := range vals {
+			if val < prev {
+				sorted = false
+				return false
+			}
+			prev = val
+		}
+		return true
+	})
+	if err != nil {
+		return false, err
+	}
+	return sorted, nil
+}
+
+// DecodeToBitset decodes the values in the byte slice and sets the bit at the
+// position of each value in bits.  Bit v is stored in bits[v/64] at position
+// v%64.  An error is returned if a value does not fit within bits.
+func DecodeToBitset(b []byte, bits []uint64) error {
+	var rangeErr error
+	err := walkWords(b, func(vals []uint64) bool {
+		for _, val := range vals {
+			if val/64 >= uint64(len(bits)) {
+				rangeErr = fmt.Errorf("value out of bitset range: %v", val)
+				return false
+			}
+			bits[val/64] |= 1 << (val % 64)
+		}
+		return true
+	})
+	if err != nil {
+		return err
+	}
+	return rangeErr
+}
+
+// EncodeFunc encodes the values returned by next until it returns false.
+func EncodeFunc(next func() (uint64, bool)) ([]byte, error) {
+	enc := NewEncoder()
+	for v, ok := next(); ok; v, ok = next() {
+		if err := enc.Write(v); err != nil {
+			return nil, err
+		}
+	}
+	return enc.Bytes()
+}
+
 // Encode packs as many values into a single uint64.  It returns the packed
 // uint64, how many values from src were packed, or an error if the values exceed
 // the maximum value range.
@@ -416,6 +983,71 @@ func EncodeAll(src []uint64) ([]uint64, error) {
 	return dst[:j], nil
 }
 
+// EncodeAllSelectors is like EncodeAll but only packs words using the selectors
+// marked true in allowed.  An error is returned if none of the allowed selectors
+// can pack the next values.  The input src is modified to avoid extra
+// allocations.  If you need to re-use, use a copy.
+func EncodeAllSelectors(src []uint64, allowed [16]bool) ([]uint64, error) {
+	i := 0
+
+	// Re-use the input slice and write encoded values back in place
+	dst := src
+	j := 0
+
+	for i < len(src) {
+		remaining := src[i:]
+
+		packed := false
+		for sel := 0; sel < 16; sel++ {
+			if !allowed[sel] || !canPack(remaining, selector[sel].n, selector[sel].bit) {
+				continue
+			}
+
+			n := selector[sel].n
+			if sel < 2 {
+				// Runs of 1's only need the selector
+				dst[j] = uint64(sel) << 60
+			} else {
+				dst[j] = selector[sel].pack(src[i : i+n])
+			}
+			i += n
+			packed = true
+			break
+		}
+
+		if !packed {
+			return nil, fmt.Errorf("no allowed selector can pack value at index %v", i)
+		}
+		j += 1
+	}
+	return dst[:j], nil
+}
+
+// EncodeAllBudget is like EncodeAll but stops once maxWords words have been
+// produced.  It returns the encoded words and the number of values from src
+// they hold, so src[consumed:] can be encoded into the next block.  The consumed
+// portion of src is modified to avoid extra allocations, the remainder is left
+// untouched.
+func EncodeAllBudget(src []uint64, maxWords int) (encoded []uint64, consumed int, err error) {
+	i := 0
+
+	// Re-use the input slice and write encoded values back in place.  Each
+	// word holds at least one value so writes never pass the read position.
+	dst := src
+	j := 0
+
+	for i < len(src) && j < maxWords {
+		v, n, err := Encode(src[i:])
+		if err != nil {
+			return nil, 0, err
+		}
+		dst[j] = v
+		i += n
+		j += 1
+	}
+	return dst[:j], i, nil
+}
+
 func Decode(dst *[240]uint64, v uint64) (n int, err error) {
 	sel := v >> 60
 	if sel >= 16 {
@@ -428,18 +1060,270 @@ func Decode(dst *[240]uint64, v uint64) (n int, err error) {
 // Decode writes the uncompressed values from src to dst.  It returns the number
 // of values written or an error.
 func DecodeAll(dst, src []uint64) (value int, err error) {
+	j := 0
+	for i := 0; i < len(src); {
+		// Consecutive words usually share a selector, so decode each run with
+		// a direct call instead of dispatching through the selector table per
+		// word.
+		sel := src[i] >> 60
+		k := i + 1
+		for k < len(src) && src[k]>>60 == sel {
+			k++
+		}
+		run := src[i:k]
+		i = k
+
+		// The unpack functions write through a [240]uint64 view of dst, so
+		// only words with 240 slots left in dst can be unpacked in place.
+		n := selector[sel].n
+		safe := 0
+		if len(dst)-j >= 240 {
+			safe = (len(dst)-j-240)/n + 1
+			if safe > len(run) {
+				safe = len(run)
+			}
+		}
+		j = decodeRun(dst, j, run[:safe], sel)
+
+		// Unpack the words near the end of dst one value at a time.
+		for _, v := range run[safe:] {
+			if j+n > len(dst) {
+				return 0, fmt.Errorf("dst too small: need at least %v", j+n)
+			}
+			unpackInto(dst[j:j+n], v, sel)
+			j += n
+		}
+	}
+	return j, nil
+}
+
+// unpackInto writes the values of word v, which uses selector sel, to dst.  dst
+// must hold exactly the number of values packed by sel.
+func unpackInto(dst []uint64, v uint64, sel uint64) {
+	if sel < 2 {
+		for i := range dst {
+			dst[i] = 1
+		}
+		return
+	}
+
+	bits := uint(selector[sel].bit)
+	mask := uint64(1)<<bits - 1
+	for i := range dst {
+		dst[i] = (v >> (uint(i) * bits)) & mask
+	}
+}
+
+// decodeRun unpacks run, whose words all use selector sel, into dst starting at
+// index j and returns the index following the last value written.  dst must
+// have at least 240 slots from the start of each word's values.
+func decodeRun(dst []uint64, j int, run []uint64, sel uint64) int {
+	switch sel {
+	case 0:
+		for _, v := range run {
+			unpack240(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 240
+		}
+	case 1:
+		for _, v := range run {
+			unpack120(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 120
+		}
+	case 2:
+		for _, v := range run {
+			unpack60(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 60
+		}
+	case 3:
+		for _, v := range run {
+			unpack30(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 30
+		}
+	case 4:
+		for _, v := range run {
+			unpack20(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 20
+		}
+	case 5:
+		for _, v := range run {
+			unpack15(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 15
+		}
+	case 6:
+		for _, v := range run {
+			unpack12(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 12
+		}
+	case 7:
+		for _, v := range run {
+			unpack10(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 10
+		}
+	case 8:
+		for _, v := range run {
+			unpack8(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 8
+		}
+	case 9:
+		for _, v := range run {
+			unpack7(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 7
+		}
+	case 10:
+		for _, v := range run {
+			unpack6(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 6
+		}
+	case 11:
+		for _, v := range run {
+			unpack5(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 5
+		}
+	case 12:
+		for _, v := range run {
+			unpack4(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 4
+		}
+	case 13:
+		for _, v := range run {
+			unpack3(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 3
+		}
+	case 14:
+		for _, v := range run {
+			unpack2(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 2
+		}
+	case 15:
+		for _, v := range run {
+			unpack1(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
+			j += 1
+		}
+	}
+	return j
+}
+
+// DecodeAllTransform writes the uncompressed values from src to dst, applying fn
+// to each value as it is written.  It returns the number of values written or
+// an error.
+func DecodeAllTransform(dst, src []uint64, fn func(uint64) uint64) (int, error) {
+	var buf [240]uint64
 	j := 0
 	for _, v := range src {
-		sel := v >> 60
-		if sel >= 16 {
-			return 0, fmt.Errorf("invalid selector value: %b", sel)
+		n, err := Decode(&buf, v)
+		if err != nil {
+			return 0, err
+		}
+
+		if j+n > len(dst) {
+			return 0, fmt.Errorf("dst too small: need at least %v", j+n)
+		}
+
+		for _, val := range buf[:n] {
+			dst[j] = fn(val)
+			j++
 		}
-		selector[sel].unpack(v, (*[240]uint64)(unsafe.Pointer(&dst[j])))
-		j += selector[sel].n
 	}
 	return j, nil
 }
 
+// DecodeAllScaled writes the uncompressed values from src to dst as floats,
+// multiplying each by scale.  This reads fixed-point columns stored as scaled
+// integers.  It returns the number of values written or an error.
+func DecodeAllScaled(dst []float64, src []uint64, scale float64) (int, error) {
+	var buf [240]uint64
+	j := 0
+	for _, v := range src {
+		n, err := Decode(&buf, v)
+		if err != nil {
+			return 0, err
+		}
+
+		if j+n > len(dst) {
+			return 0, fmt.Errorf("dst too small: need at least %v", j+n)
+		}
+
+		for _, val := range buf[:n] {
+			dst[j] = float64(val) * scale
+			j++
+		}
+	}
+	return j, nil
+}
+
+// DecodeAllPartial writes the uncompressed values from src to dst, stopping at
+// the first word that cannot be decoded rather than failing the whole call.  A
+// word cannot be decoded if it has data in bits its selector leaves unused (see
+// Decoder.SetLenientMode).  It returns the number of values written and the
+// number of words from the first bad word to the end of src.  An error is only
+// returned if dst is too small to hold the decoded values.
+func DecodeAllPartial(dst, src []uint64) (valuesWritten int, trailingWords int, err error) {
+	var buf [240]uint64
+	j := 0
+	for i, v := range src {
+		if hasUnknownMarker(v) {
+			return j, len(src) - i, nil
+		}
+
+		n, err := Decode(&buf, v)
+		if err != nil {
+			return j, len(src) - i, nil
+		}
+
+		if j+n > len(dst) {
+			return j, len(src) - i, fmt.Errorf("dst too small: need at least %v", j+n)
+		}
+		copy(dst[j:], buf[:n])
+		j += n
+	}
+	return j, 0, nil
+}
+
+// DecodeAllSkip writes the uncompressed values from src to dst, ignoring the
+// first skip words of src.  This is useful when src is prefixed with header words
+// that are not part of the encoded values.  It returns the number of values
+// written or an error.
+func DecodeAllSkip(dst, src []uint64, skip int) (value int, err error) {
+	if skip < 0 || skip > len(src) {
+		return 0, fmt.Errorf("invalid skip value: %v", skip)
+	}
+	return DecodeAll(dst, src[skip:])
+}
+
+// DecodeAllReuse decodes the words held in buf back into buf, avoiding a separate
+// destination slice.  The encoded words must occupy buf[:len(buf)] and cap(buf)
+// must be large enough to hold all of the decoded values, which can be determined
+// with CountBytes.  Words are decoded from last to first so that no word is
+// overwritten before it is read.  It returns the number of values written to
+// buf[:cap(buf)] or an error.
+func DecodeAllReuse(buf []uint64) (int, error) {
+	total := 0
+	for _, v := range buf {
+		n, err := Count(v)
+		if err != nil {
+			return 0, err
+		}
+		total += n
+	}
+
+	if total > cap(buf) {
+		return 0, fmt.Errorf("buffer capacity too small: need %v, have %v", total, cap(buf))
+	}
+
+	dst := buf[:cap(buf)]
+	var tmp [240]uint64
+	j := total
+	for i := len(buf) - 1; i >= 0; i-- {
+		n, err := Decode(&tmp, buf[i])
+		if err != nil {
+			return 0, err
+		}
+		j -= n
+		copy(dst[j:j+n], tmp[:n])
+	}
+	return total, nil
+}
+
 // canPack returs true if n elements from in can be stored using bits per element
 func canPack(src []uint64, n, bits int) bool {
 	if len(src) < n {
@@ -743,7 +1627,7 @@ func unpack240(v uint64, dst *[240]uint64) {
 }
 
 func unpack120(v uint64, dst *[240]uint64) {
-	for i := range dst {
+	for i := 0; i < 120; i++ {
 		dst[i] = 1
 	}
 }
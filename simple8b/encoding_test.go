@@ -1,6 +1,9 @@
 package simple8b_test
 
 import (
+	"encoding/binary"
+	"io"
+	"math"
 	"testing"
 
 	"github.com/jwilder/encoding/simple8b"
@@ -150,125 +153,1587 @@ func testEncode(t *testing.T, n int, val uint64) {
 
 }
 
+func encodeValues(t *testing.T, values []uint64) []byte {
+	enc := simple8b.NewEncoder()
+	for _, v := range values {
+		enc.Write(v)
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	return b
+}
+
+// encodeSelectorMix returns the encoding of 240 ones, 60 zeros and 1<<50, which
+// is three words: a run of ones (selector 0), 60 one bit values (selector 2) and
+// one large value (selector 15).
+func encodeSelectorMix(t *testing.T) []byte {
+	in := make([]uint64, 301)
+	for i := 0; i < 240; i++ {
+		in[i] = 1
+	}
+	in[300] = 1 << 50
+
+	b := encodeValues(t, in)
+	if exp, got := 24, len(b); got != exp {
+		t.Fatalf("Encode len mismatch: exp %v, got %v", exp, got)
+	}
+	return b
+}
+
 func Test_Bytes(t *testing.T) {
 	enc := simple8b.NewEncoder()
 	for i := 0; i < 30; i++ {
 		enc.Write(uint64(i))
 	}
-	b, _ := enc.Bytes()
+	b, _ := enc.Bytes()
+
+	dec := simple8b.NewDecoder(b)
+	x := uint64(0)
+	for dec.Next() {
+		if x != dec.Read() {
+			t.Fatalf("mismatch: got %v, exp %v", dec.Read(), x)
+		}
+		x += 1
+	}
+}
+
+func TestEncoder_ValueCount(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 1000; i++ {
+		if exp, got := i, enc.ValueCount(); got != exp {
+			t.Fatalf("ValueCount mismatch: exp %v, got %v", exp, got)
+		}
+		enc.Write(uint64(i % 16))
+	}
+
+	if _, err := enc.Bytes(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 1000, enc.ValueCount(); got != exp {
+		t.Fatalf("ValueCount mismatch: exp %v, got %v", exp, got)
+	}
+
+	enc.Reset()
+	if exp, got := 0, enc.ValueCount(); got != exp {
+		t.Fatalf("ValueCount mismatch after reset: exp %v, got %v", exp, got)
+	}
+}
+
+func TestEncoder_Grow(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	enc.Write(5)
+	enc.Grow(1000)
+
+	in := []uint64{5}
+	for i := 0; i < 1000; i++ {
+		in = append(in, uint64(i))
+		enc.Write(uint64(i))
+	}
+
+	encoded, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded := make([]uint64, 0, len(in))
+	dec := simple8b.NewDecoder(encoded)
+	for dec.Next() {
+		decoded = append(decoded, dec.Read())
+	}
+
+	if exp, got := len(in), len(decoded); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+}
+
+func TestDecoder_Position(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 500; i++ {
+		enc.Write(uint64(i % 7))
+	}
+	b, _ := enc.Bytes()
+
+	dec := simple8b.NewDecoder(b)
+	if exp, got := 0, dec.Position(); got != exp {
+		t.Fatalf("Position mismatch: exp %v, got %v", exp, got)
+	}
+
+	i := 0
+	for dec.Next() {
+		i += 1
+		if exp, got := i, dec.Position(); got != exp {
+			t.Fatalf("Position mismatch: exp %v, got %v", exp, got)
+		}
+	}
+
+	if exp, got := 500, dec.Position(); got != exp {
+		t.Fatalf("Position mismatch: exp %v, got %v", exp, got)
+	}
+
+	dec.SetBytes(b)
+	if exp, got := 0, dec.Position(); got != exp {
+		t.Fatalf("Position mismatch after SetBytes: exp %v, got %v", exp, got)
+	}
+}
+
+func TestEncoder_WithLengthPrefix(t *testing.T) {
+	enc := simple8b.NewEncoder(simple8b.WithLengthPrefix())
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 100)
+		enc.Write(in[i])
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	count, err := simple8b.CountBytes(b[8:])
+	if err != nil {
+		t.Fatalf("Unexpected error in Count: %v", err)
+	}
+
+	dec, n, err := simple8b.NewLengthPrefixedDecoder(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if n != count {
+		t.Fatalf("Length prefix mismatch: exp %v, got %v", count, n)
+	}
+
+	i := 0
+	for dec.Next() {
+		if dec.Read() != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dec.Read())
+		}
+		i += 1
+	}
+
+	if exp, got := len(in), i; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	// The prefix is rewritten after a reset
+	enc.Reset()
+	enc.Write(5)
+	b, err = enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, n, _ := simple8b.NewLengthPrefixedDecoder(b); n != 1 {
+		t.Fatalf("Length prefix mismatch after reset: exp %v, got %v", 1, n)
+	}
+}
+
+func TestEncoder_WordSerializer(t *testing.T) {
+	// Each word is written as a length byte followed by its minimal big
+	// endian bytes.
+	serialize := func(dst []byte, word uint64) int {
+		var tmp [8]byte
+		binary.BigEndian.PutUint64(tmp[:], word)
+		i := 0
+		for i < 7 && tmp[i] == 0 {
+			i++
+		}
+		dst[0] = byte(8 - i)
+		return 1 + copy(dst[1:], tmp[i:])
+	}
+	deserialize := func(src []byte) (uint64, int) {
+		if len(src) == 0 || len(src) < 1+int(src[0]) {
+			return 0, 0
+		}
+		var v uint64
+		for _, c := range src[1 : 1+int(src[0])] {
+			v = v<<8 | uint64(c)
+		}
+		return v, 1 + int(src[0])
+	}
+
+	enc := simple8b.NewEncoder()
+	enc.SetWordSerializer(serialize)
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 7)
+		if i > 500 {
+			in[i] = 1
+		}
+		enc.Write(in[i])
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dec := simple8b.NewDecoder(b)
+	dec.SetWordDeserializer(deserialize)
+	i := 0
+	for dec.Next() {
+		if dec.Read() != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dec.Read())
+		}
+		i += 1
+	}
+
+	if exp, got := len(in), i; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestEncoder_TakeChunk(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	enc.SetFlushThreshold(64)
+
+	in := make([]uint64, 10000)
+	var (
+		b      []byte
+		chunks int
+	)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 1000)
+		if err := enc.Write(in[i]); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if enc.ChunkReady() {
+			chunk := enc.TakeChunk()
+			if len(chunk) < 64 {
+				t.Fatalf("Chunk len mismatch: exp at least %v, got %v", 64, len(chunk))
+			}
+			b = append(b, chunk...)
+			chunks++
+		}
+	}
+
+	if chunks < 2 {
+		t.Fatalf("Expected multiple chunks, got %v", chunks)
+	}
+
+	if enc.TakeChunk() != nil {
+		t.Fatalf("Expected no chunk ready")
+	}
+
+	rest, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	b = append(b, rest...)
+
+	dec := simple8b.NewDecoder(b)
+	i := 0
+	for dec.Next() {
+		if dec.Read() != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dec.Read())
+		}
+		i += 1
+	}
+
+	if exp, got := len(in), i; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestEncoder_TakeChunk_LengthPrefix(t *testing.T) {
+	enc := simple8b.NewEncoder(simple8b.WithLengthPrefix())
+	enc.SetFlushThreshold(64)
+
+	in := make([]uint64, 10000)
+	var blocks [][]byte
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 1000)
+		if err := enc.Write(in[i]); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if enc.ChunkReady() {
+			blocks = append(blocks, enc.TakeChunk())
+		}
+	}
+
+	if len(blocks) < 2 {
+		t.Fatalf("Expected multiple chunks, got %v", len(blocks))
+	}
+
+	rest, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	blocks = append(blocks, rest)
+
+	// Each chunk and the final output is prefixed with its own value count
+	i := 0
+	for _, b := range blocks {
+		dec, n, err := simple8b.NewLengthPrefixedDecoder(b)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		start := i
+		for dec.Next() {
+			if dec.Read() != in[i] {
+				t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dec.Read())
+			}
+			i += 1
+		}
+
+		if exp, got := n, i-start; got != exp {
+			t.Fatalf("Prefix count mismatch: exp %v, got %v", exp, got)
+		}
+	}
+
+	if exp, got := len(in), i; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestEncoder_BytesWithCount(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 1234; i++ {
+		enc.Write(uint64(i % 3))
+	}
+
+	b, n, err := enc.BytesWithCount()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	count, err := simple8b.CountBytes(b)
+	if err != nil {
+		t.Fatalf("Unexpected error in Count: %v", err)
+	}
+
+	if n != count || n != 1234 {
+		t.Fatalf("Count mismatch: exp %v, got %v", count, n)
+	}
+}
+
+func TestEncoder_BytesCopy(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 30; i++ {
+		enc.Write(uint64(i))
+	}
+
+	copied, err := enc.BytesCopy()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	aliased, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	before := append([]byte(nil), aliased...)
+
+	enc.Reset()
+	for i := 0; i < 30; i++ {
+		enc.Write(uint64(100 + i))
+	}
+	if _, err := enc.Bytes(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if string(aliased) == string(before) {
+		t.Fatalf("Expected Bytes result to be overwritten after Reset")
+	}
+
+	if string(copied) != string(before) {
+		t.Fatalf("BytesCopy result modified after Reset")
+	}
+
+	dec := simple8b.NewDecoder(copied)
+	x := uint64(0)
+	for dec.Next() {
+		if x != dec.Read() {
+			t.Fatalf("mismatch: got %v, exp %v", dec.Read(), x)
+		}
+		x += 1
+	}
+}
+
+func Test_Encode_ValueTooLarge(t *testing.T) {
+	enc := simple8b.NewEncoder()
+
+	values := []uint64{
+		1442369134000000000, 0,
+	}
+
+	for _, v := range values {
+		enc.Write(v)
+	}
+
+	_, err := enc.Bytes()
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+
+	}
+}
+
+func Test_Decode_NotEnoughBytes(t *testing.T) {
+	dec := simple8b.NewDecoder([]byte{0})
+	if dec.Next() {
+		t.Fatalf("Expected Next to return false but it returned true")
+	}
+}
+
+func TestDecoder_ReadInto(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 1000; i++ {
+		v := uint64(i % 37)
+		if i > 600 && i < 900 {
+			v = 1
+		}
+		enc.Write(v)
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	words := make([]uint64, len(b)/8)
+	for i := range words {
+		words[i] = binary.BigEndian.Uint64(b[i*8:])
+	}
+
+	exp := make([]uint64, 1000)
+	if _, err := simple8b.DecodeAll(exp, words); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dec := simple8b.NewDecoder(b)
+	var got []uint64
+	chunk := make([]uint64, 100)
+	for {
+		n, err := dec.ReadInto(chunk)
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		got = append(got, chunk[:n]...)
+	}
+
+	if len(got) != len(exp) {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", len(exp), len(got))
+	}
+
+	for i := range exp {
+		if got[i] != exp[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, exp[i], got[i])
+		}
+	}
+
+	if exp, got := len(exp), dec.Position(); got != exp {
+		t.Fatalf("Position mismatch: exp %v, got %v", exp, got)
+	}
+
+	// Next continues after values copied by ReadInto
+	dec.SetBytes(b)
+	dec.Next()
+	if n, err := dec.ReadInto(chunk[:10]); err != nil || n != 10 {
+		t.Fatalf("ReadInto mismatch: exp 10, got %v, %v", n, err)
+	}
+	if !dec.Next() || dec.Read() != exp[11] {
+		t.Fatalf("Decoded[%d] != %v, got %v", 11, exp[11], dec.Read())
+	}
+}
+
+func TestDecoder_LenientMode(t *testing.T) {
+	words := []uint64{
+		14<<60 | 1 | 2<<30, // 2 values
+		1<<60 | 5,          // run selector with payload bits
+		14<<60 | 3 | 4<<30, // 2 values
+		8<<60 | 1<<56 | 7,  // selector 8 with unused bits set
+	}
+
+	b := make([]byte, len(words)*8)
+	for i, w := range words {
+		binary.BigEndian.PutUint64(b[i*8:], w)
+	}
+
+	dec := simple8b.NewDecoder(b)
+	dec.SetLenientMode(true)
+
+	var got []uint64
+	for dec.Next() {
+		got = append(got, dec.Read())
+	}
+
+	exp := []uint64{1, 2, 3, 4}
+	if len(got) != len(exp) {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", len(exp), len(got))
+	}
+
+	for i := 0; i < len(exp); i++ {
+		if got[i] != exp[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, exp[i], got[i])
+		}
+	}
+
+	if exp, got := 2, dec.SkippedWords(); got != exp {
+		t.Fatalf("Skipped words mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestCountBytesBetween(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	in := make([]uint64, 8)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i)
+		enc.Write(in[i])
+	}
+
+	encoded, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dec := simple8b.NewDecoder(encoded)
+	i := 0
+	for dec.Next() {
+		if i >= len(in) {
+			t.Fatalf("Decoded too many values: got %v, exp %v", i, len(in))
+		}
+
+		if dec.Read() != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dec.Read())
+		}
+		i += 1
+	}
+
+	if exp, got := len(in), i; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	got, err := simple8b.CountBytesBetween(encoded, 2, 6)
+	if err != nil {
+		t.Fatalf("Unexpected error in Count: %v", err)
+	}
+	if got != 4 {
+		t.Fatalf("Count mismatch: got %v, exp %v", got, 4)
+	}
+}
+
+func TestCountBytesBetween_SkipMin(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	in := make([]uint64, 8)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i)
+		enc.Write(in[i])
+	}
+	in = append(in, 100000)
+	enc.Write(100000)
+
+	encoded, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dec := simple8b.NewDecoder(encoded)
+	i := 0
+	for dec.Next() {
+		if i >= len(in) {
+			t.Fatalf("Decoded too many values: got %v, exp %v", i, len(in))
+		}
+
+		if dec.Read() != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dec.Read())
+		}
+		i += 1
+	}
+
+	if exp, got := len(in), i; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	got, err := simple8b.CountBytesBetween(encoded, 100000, 100001)
+	if err != nil {
+		t.Fatalf("Unexpected error in Count: %v", err)
+	}
+	if got != 1 {
+		t.Fatalf("Count mismatch: got %v, exp %v", got, 1)
+	}
+}
+
+func TestEachWord(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	var exp uint64
+	for i := 0; i < 1000; i++ {
+		v := uint64(i % 250)
+		if i < 240 {
+			v = 1
+		}
+		exp += v
+		enc.Write(v)
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var sum uint64
+	var words int
+	if err := simple8b.EachWord(b, func(vals []uint64) {
+		words++
+		for _, v := range vals {
+			sum += v
+		}
+	}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if sum != exp {
+		t.Fatalf("Sum mismatch: exp %v, got %v", exp, sum)
+	}
+
+	if exp := len(b) / 8; words != exp {
+		t.Fatalf("Word count mismatch: exp %v, got %v", exp, words)
+	}
+}
+
+func TestCanConcat(t *testing.T) {
+	a := encodeValues(t, []uint64{1, 2, 3})
+	b := encodeValues(t, []uint64{4, 5, 6, 7})
+
+	ok, err := simple8b.CanConcat(a, b)
+	if err != nil || !ok {
+		t.Fatalf("CanConcat mismatch: exp true, got %v, %v", ok, err)
+	}
+
+	joined := append(append([]byte(nil), a...), b...)
+	n, err := simple8b.CountBytes(joined)
+	if err != nil {
+		t.Fatalf("Unexpected error in Count: %v", err)
+	}
+	if n != 7 {
+		t.Fatalf("Count mismatch: got %v, exp %v", n, 7)
+	}
+
+	if ok, err := simple8b.CanConcat(a, b[:len(b)-1]); ok || err == nil {
+		t.Fatalf("CanConcat mismatch: exp false, got %v, %v", ok, err)
+	}
+
+	if ok, err := simple8b.CanConcat(a[1:], b); ok || err == nil {
+		t.Fatalf("CanConcat mismatch: exp false, got %v, %v", ok, err)
+	}
+}
+
+func TestValuesUpToByte(t *testing.T) {
+	b := encodeSelectorMix(t)
+
+	// Cap lands in the middle of the third word
+	values, err := simple8b.ValuesUpToByte(b, 20)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 300, len(values); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i, v := range values {
+		exp := uint64(0)
+		if i < 240 {
+			exp = 1
+		}
+		if v != exp {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, exp, v)
+		}
+	}
+
+	values, err = simple8b.ValuesUpToByte(b, 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 301, len(values); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestWordIndexOf(t *testing.T) {
+	b := encodeSelectorMix(t)
+
+	tests := []struct {
+		index        int
+		word, offset int
+	}{
+		{0, 0, 0},
+		{239, 0, 239},
+		{240, 1, 0},
+		{299, 1, 59},
+		{300, 2, 0},
+	}
+
+	for _, test := range tests {
+		word, offset, err := simple8b.WordIndexOf(b, test.index)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if word != test.word || offset != test.offset {
+			t.Fatalf("WordIndexOf(%d) mismatch: exp %v, %v, got %v, %v", test.index, test.word, test.offset, word, offset)
+		}
+	}
+
+	if _, _, err := simple8b.WordIndexOf(b, 301); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestOffsetTable(t *testing.T) {
+	b := encodeSelectorMix(t)
+
+	byteOffsets, valueOffsets, err := simple8b.OffsetTable(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expBytes := []int{0, 8, 16}
+	expValues := []int{0, 240, 300}
+	if len(byteOffsets) != len(expBytes) || len(valueOffsets) != len(expValues) {
+		t.Fatalf("OffsetTable len mismatch: exp %v, got %v and %v", len(expBytes), len(byteOffsets), len(valueOffsets))
+	}
+
+	for i := range expBytes {
+		if byteOffsets[i] != expBytes[i] {
+			t.Fatalf("ByteOffset[%d] != %v, got %v", i, expBytes[i], byteOffsets[i])
+		}
+		if valueOffsets[i] != expValues[i] {
+			t.Fatalf("ValueOffset[%d] != %v, got %v", i, expValues[i], valueOffsets[i])
+		}
+	}
+
+	if _, _, err := simple8b.OffsetTable(b[:len(b)-1]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestEstimateBytes(t *testing.T) {
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 300)
+	}
+
+	if exp, got := len(encodeValues(t, in)), simple8b.EstimateBytes(in); got != exp {
+		t.Fatalf("EstimateBytes mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if exp := uint64(i % 300); in[i] != exp {
+			t.Fatalf("Input[%d] modified: exp %v, got %v", i, exp, in[i])
+		}
+	}
+
+	if exp, got := 16, simple8b.EstimateBytes([]uint64{1 << 62, 1}); got != exp {
+		t.Fatalf("EstimateBytes mismatch for large value: exp %v, got %v", exp, got)
+	}
+}
+
+func TestWordsNeeded(t *testing.T) {
+	tests := []struct {
+		count, bits int
+		exp         int
+	}{
+		{0, 4, 0},
+		{1, 0, 1},
+		{60, 1, 1},
+		{61, 1, 2},
+		{15, 4, 1},
+		{16, 4, 2},
+		{100, 9, 17},
+		{12, 11, 3},
+		{3, 60, 3},
+		{1, 61, -1},
+	}
+
+	for _, test := range tests {
+		if got := simple8b.WordsNeeded(test.count, test.bits); got != test.exp {
+			t.Fatalf("WordsNeeded(%d, %d) mismatch: exp %v, got %v", test.count, test.bits, test.exp, got)
+		}
+	}
+}
+
+func TestSum(t *testing.T) {
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i * 31 % 1000)
+	}
+	for i := 0; i < 240; i++ {
+		in[i] = 1
+	}
+
+	b := encodeValues(t, in)
+
+	values := make([]uint64, len(in))
+	copy(values, in)
+	encoded, err := simple8b.EncodeAll(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded := make([]uint64, len(in))
+	n, err := simple8b.DecodeAll(decoded, encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	var exp uint64
+	for _, v := range decoded[:n] {
+		exp += v
+	}
+
+	got, err := simple8b.Sum(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if got != exp {
+		t.Fatalf("Sum mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestSum_Overflow(t *testing.T) {
+	in := make([]uint64, 17)
+	for i := 0; i < 16; i++ {
+		in[i] = simple8b.MaxValue
+	}
+	in[16] = 5
+
+	got, err := simple8b.Sum(encodeValues(t, in))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// 16 * (2^60 - 1) + 5 wraps around to 5 - 16
+	var exp uint64
+	for _, v := range in {
+		exp += v
+	}
+	if exp != math.MaxUint64-10 || got != exp {
+		t.Fatalf("Sum mismatch: exp %v, got %v", uint64(math.MaxUint64-10), got)
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 500; i++ {
+		enc.Write(uint64(50 + i%100))
+	}
+	enc.Write(7)
+	enc.Write(1 << 40)
+
+	encoded, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	min, max, err := simple8b.MinMax(encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if min != 7 || max != 1<<40 {
+		t.Fatalf("MinMax mismatch: exp %v, %v, got %v, %v", 7, uint64(1<<40), min, max)
+	}
+}
+
+func TestMinMax_Ones(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 480; i++ {
+		enc.Write(1)
+	}
+
+	encoded, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	min, max, err := simple8b.MinMax(encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if min != 1 || max != 1 {
+		t.Fatalf("MinMax mismatch: exp %v, %v, got %v, %v", 1, 1, min, max)
+	}
+}
+
+func TestDecodeGrouped(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	var in []uint64
+	for i := 0; i < 120; i++ {
+		in = append(in, 1)
+	}
+	for i := 0; i < 15; i++ {
+		in = append(in, 9)
+	}
+	in = append(in, 1<<40)
+	for _, v := range in {
+		enc.Write(v)
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	groups, err := simple8b.DecodeGrouped(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(b)/8, len(groups); got != exp {
+		t.Fatalf("Group len mismatch: exp %v, got %v", exp, got)
+	}
+
+	i := 0
+	for w, group := range groups {
+		n, err := simple8b.Count(binary.BigEndian.Uint64(b[w*8:]))
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(group) != n {
+			t.Fatalf("Group[%d] size mismatch: exp %v, got %v", w, n, len(group))
+		}
+
+		for _, v := range group {
+			if v != in[i] {
+				t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], v)
+			}
+			i++
+		}
+	}
+}
+
+func TestIsAllOnes(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 360; i++ {
+		enc.Write(1)
+	}
+
+	encoded, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ones, err := simple8b.IsAllOnes(encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !ones {
+		t.Fatalf("IsAllOnes mismatch: exp true, got false")
+	}
+}
+
+func TestIsAllOnes_Mixed(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 240; i++ {
+		enc.Write(1)
+	}
+	enc.Write(2)
+
+	encoded, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ones, err := simple8b.IsAllOnes(encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if ones {
+		t.Fatalf("IsAllOnes mismatch: exp false, got true")
+	}
+}
+
+func TestIsSortedBytes(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 1000; i++ {
+		enc.Write(uint64(i / 3))
+	}
+
+	encoded, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sorted, err := simple8b.IsSortedBytes(encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !sorted {
+		t.Fatalf("IsSortedBytes mismatch: exp true, got false")
+	}
+}
+
+func TestIsSortedBytes_OutOfOrder(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 1000; i++ {
+		if i == 500 {
+			enc.Write(0)
+			continue
+		}
+		enc.Write(uint64(i))
+	}
+
+	encoded, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	sorted, err := simple8b.IsSortedBytes(encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if sorted {
+		t.Fatalf("IsSortedBytes mismatch: exp false, got true")
+	}
+}
+
+func TestDecodeToBitset(t *testing.T) {
+	ids := []uint64{0, 3, 5, 63, 64, 65, 127, 200, 255, 3}
+
+	enc := simple8b.NewEncoder()
+	for _, v := range ids {
+		enc.Write(v)
+	}
+
+	encoded, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	bits := make([]uint64, 4)
+	if err := simple8b.DecodeToBitset(encoded, bits); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	exp := make([]uint64, 4)
+	for _, v := range ids {
+		exp[v/64] |= 1 << (v % 64)
+	}
+
+	for i := 0; i < len(exp); i++ {
+		if bits[i] != exp[i] {
+			t.Fatalf("Bitset[%d] != %b, got %b", i, exp[i], bits[i])
+		}
+	}
+
+	if err := simple8b.DecodeToBitset(encoded, make([]uint64, 2)); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestDecodeAll_120Ones_NoClobber(t *testing.T) {
+	// A selector 1 word encoding 120 ones followed by a word of 2 values
+	src := []uint64{1 << 60, 14<<60 | 5 | 6<<30}
+
+	dst := make([]uint64, 240)
+	for i := range dst {
+		dst[i] = 99
+	}
+
+	n, err := simple8b.DecodeAll(dst, src[:1])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 120, n; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < 120; i++ {
+		if dst[i] != 1 {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, 1, dst[i])
+		}
+	}
+
+	for i := 120; i < len(dst); i++ {
+		if dst[i] != 99 {
+			t.Fatalf("Clobbered[%d]: exp %v, got %v", i, 99, dst[i])
+		}
+	}
+
+	n, err = simple8b.DecodeAll(dst, src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 122, n; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	if dst[120] != 5 || dst[121] != 6 {
+		t.Fatalf("Decoded adjacent word mismatch: got %v, %v", dst[120], dst[121])
+	}
+
+	for i := 122; i < len(dst); i++ {
+		if dst[i] != 99 {
+			t.Fatalf("Clobbered[%d]: exp %v, got %v", i, 99, dst[i])
+		}
+	}
+}
+
+func TestDecodeAll_SelectorRuns(t *testing.T) {
+	// Alternate between runs of words sharing a selector and single words
+	var in []uint64
+	for i := 0; i < 2000; i++ {
+		in = append(in, uint64(i%16))
+	}
+	for i := 0; i < 500; i++ {
+		in = append(in, 1)
+	}
+	for i := 0; i < 100; i++ {
+		in = append(in, uint64(i)<<uint(i%40))
+	}
+
+	values := make([]uint64, len(in))
+	copy(values, in)
+	encoded, err := simple8b.EncodeAll(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded := make([]uint64, len(in))
+	n, err := simple8b.DecodeAll(decoded, encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), n; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+}
+
+func TestDecodeAll_ExactDst(t *testing.T) {
+	// 7 bit values pack 8 per word (selector 8), so 24 values fill 3 words
+	in := make([]uint64, 24)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(100 + i)
+	}
+
+	values := make([]uint64, len(in))
+	copy(values, in)
+	encoded, err := simple8b.EncodeAll(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 3, len(encoded); got != exp {
+		t.Fatalf("Encode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	// dst is a window into a larger slice so writes past its end would be
+	// visible in the guard values.
+	backing := make([]uint64, len(in)+8)
+	for i := range backing {
+		backing[i] = 99
+	}
+	dst := backing[:len(in):len(in)]
+
+	n, err := simple8b.DecodeAll(dst, encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), n; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if dst[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dst[i])
+		}
+	}
+
+	for i := len(in); i < len(backing); i++ {
+		if backing[i] != 99 {
+			t.Fatalf("Clobbered[%d]: exp %v, got %v", i, 99, backing[i])
+		}
+	}
+
+	if _, err := simple8b.DecodeAll(dst[:len(in)-1], encoded); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestDecodeAllTransform(t *testing.T) {
+	in := make([]uint64, 500)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 37)
+	}
 
-	dec := simple8b.NewDecoder(b)
-	x := uint64(0)
-	for dec.Next() {
-		if x != dec.Read() {
-			t.Fatalf("mismatch: got %v, exp %v", dec.Read(), x)
+	values := make([]uint64, len(in))
+	copy(values, in)
+	encoded, err := simple8b.EncodeAll(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	exp := make([]uint64, len(in))
+	n, err := simple8b.DecodeAll(exp, encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	for i := 0; i < n; i++ {
+		exp[i] *= 2
+	}
+
+	got := make([]uint64, len(in))
+	m, err := simple8b.DecodeAllTransform(got, encoded, func(v uint64) uint64 { return v * 2 })
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if m != n {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", n, m)
+	}
+
+	for i := 0; i < n; i++ {
+		if got[i] != exp[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, exp[i], got[i])
 		}
-		x += 1
+	}
+
+	if _, err := simple8b.DecodeAllTransform(got[:10], encoded, func(v uint64) uint64 { return v }); err == nil {
+		t.Fatalf("Expected error, got nil")
 	}
 }
 
-func Test_Encode_ValueTooLarge(t *testing.T) {
-	enc := simple8b.NewEncoder()
+func TestDecodeAllScaled(t *testing.T) {
+	in := make([]uint64, 500)
+	for i := 0; i < len(in); i++ {
+		// Prices with 3 decimal places stored as thousandths
+		in[i] = uint64(19990 + i*7)
+	}
 
-	values := []uint64{
-		1442369134000000000, 0,
+	values := make([]uint64, len(in))
+	copy(values, in)
+	encoded, err := simple8b.EncodeAll(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	for _, v := range values {
-		enc.Write(v)
+	decoded := make([]uint64, len(in))
+	n, err := simple8b.DecodeAll(decoded, encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	_, err := enc.Bytes()
-	if err == nil {
-		t.Fatalf("Expected error, got nil")
+	got := make([]float64, len(in))
+	m, err := simple8b.DecodeAllScaled(got, encoded, 0.001)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if m != n {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", n, m)
+	}
+
+	for i := 0; i < n; i++ {
+		if exp := float64(decoded[i]) * 0.001; got[i] != exp {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, exp, got[i])
+		}
+	}
 
+	if _, err := simple8b.DecodeAllScaled(got[:10], encoded, 1); err == nil {
+		t.Fatalf("Expected error, got nil")
 	}
 }
 
-func Test_Decode_NotEnoughBytes(t *testing.T) {
-	dec := simple8b.NewDecoder([]byte{0})
-	if dec.Next() {
-		t.Fatalf("Expected Next to return false but it returned true")
+func TestDecodeAllPartial(t *testing.T) {
+	src := []uint64{
+		14<<60 | 1 | 2<<30, // 2 values
+		13<<60 | 3 | 4<<20 | 5<<40,
+		0<<60 | 12345,      // corrupt run selector
+		14<<60 | 6 | 7<<30, // 2 values
+	}
+
+	dst := make([]uint64, 240)
+	n, trailing, err := simple8b.DecodeAllPartial(dst, src)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 5, n; got != exp {
+		t.Fatalf("Values written mismatch: exp %v, got %v", exp, got)
+	}
+
+	if exp, got := 2, trailing; got != exp {
+		t.Fatalf("Trailing words mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < n; i++ {
+		if exp := uint64(i + 1); dst[i] != exp {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, exp, dst[i])
+		}
+	}
+
+	n, trailing, err = simple8b.DecodeAllPartial(dst, src[:2])
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if n != 5 || trailing != 0 {
+		t.Fatalf("DecodeAllPartial mismatch: exp 5, 0, got %v, %v", n, trailing)
 	}
 }
 
-func TestCountBytesBetween(t *testing.T) {
-	enc := simple8b.NewEncoder()
-	in := make([]uint64, 8)
+func TestEncodeAllSelectors(t *testing.T) {
+	in := make([]uint64, 240)
 	for i := 0; i < len(in); i++ {
-		in[i] = uint64(i)
-		enc.Write(in[i])
+		in[i] = 1
 	}
 
-	encoded, err := enc.Bytes()
+	var allowed [16]bool
+	for i := 2; i < 16; i++ {
+		allowed[i] = true
+	}
+
+	values := make([]uint64, len(in))
+	copy(values, in)
+	encoded, err := simple8b.EncodeAllSelectors(values, allowed)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	dec := simple8b.NewDecoder(encoded)
-	i := 0
-	for dec.Next() {
-		if i >= len(in) {
-			t.Fatalf("Decoded too many values: got %v, exp %v", i, len(in))
-		}
+	if exp, got := 4, len(encoded); got != exp {
+		t.Fatalf("Encode len mismatch: exp %v, got %v", exp, got)
+	}
 
-		if dec.Read() != in[i] {
-			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dec.Read())
+	for i, v := range encoded {
+		if sel := v >> 60; sel != 2 {
+			t.Fatalf("Selector[%d] mismatch: exp %v, got %v", i, 2, sel)
 		}
-		i += 1
 	}
 
-	if exp, got := len(in), i; got != exp {
+	decoded := make([]uint64, len(in))
+	n, err := simple8b.DecodeAll(decoded, encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), n; got != exp {
 		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
 	}
 
-	got, err := simple8b.CountBytesBetween(encoded, 2, 6)
-	if err != nil {
-		t.Fatalf("Unexpected error in Count: %v", err)
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
 	}
-	if got != 4 {
-		t.Fatalf("Count mismatch: got %v, exp %v", got, 4)
+}
+
+func TestEncodeAllSelectors_NoneFit(t *testing.T) {
+	var allowed [16]bool
+	allowed[2] = true
+
+	// Enough values for selector 2, but one needs 2 bits
+	in := make([]uint64, 120)
+	in[30] = 2
+
+	if _, err := simple8b.EncodeAllSelectors(in, allowed); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	// The same count of 1 bit values packs
+	in[30] = 1
+	if _, err := simple8b.EncodeAllSelectors(in, allowed); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
 	}
 }
 
-func TestCountBytesBetween_SkipMin(t *testing.T) {
-	enc := simple8b.NewEncoder()
-	in := make([]uint64, 8)
+func TestEncodeAllBudget(t *testing.T) {
+	in := make([]uint64, 1000)
 	for i := 0; i < len(in); i++ {
-		in[i] = uint64(i)
-		enc.Write(in[i])
+		in[i] = uint64(i % 50)
 	}
-	in = append(in, 100000)
-	enc.Write(100000)
 
-	encoded, err := enc.Bytes()
+	values := make([]uint64, len(in))
+	copy(values, in)
+
+	first, consumed, err := simple8b.EncodeAllBudget(values, 10)
 	if err != nil {
 		t.Fatalf("Unexpected error: %v", err)
 	}
 
-	dec := simple8b.NewDecoder(encoded)
+	if exp, got := 10, len(first); got != exp {
+		t.Fatalf("Encode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	// Keep the first block before the second call reuses the rest of values
+	first = append([]uint64(nil), first...)
+
+	rest, restConsumed, err := simple8b.EncodeAllBudget(values[consumed:], 1000)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), consumed+restConsumed; got != exp {
+		t.Fatalf("Consumed mismatch: exp %v, got %v", exp, got)
+	}
+
+	decoded := make([]uint64, len(in))
+	n, err := simple8b.DecodeAll(decoded, first)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if n != consumed {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", consumed, n)
+	}
+
+	m, err := simple8b.DecodeAll(decoded[n:], rest)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), n+m; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+}
+
+func TestEncodeFunc(t *testing.T) {
 	i := 0
+	b, err := simple8b.EncodeFunc(func() (uint64, bool) {
+		if i >= 1000 {
+			return 0, false
+		}
+		i++
+		return uint64(i * i), true
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	dec := simple8b.NewDecoder(b)
+	n := 0
 	for dec.Next() {
-		if i >= len(in) {
-			t.Fatalf("Decoded too many values: got %v, exp %v", i, len(in))
+		n++
+		if exp := uint64(n * n); dec.Read() != exp {
+			t.Fatalf("Decoded[%d] != %v, got %v", n-1, exp, dec.Read())
 		}
+	}
 
-		if dec.Read() != in[i] {
-			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dec.Read())
+	if exp, got := 1000, n; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestDecodeAllSkip(t *testing.T) {
+	in := make([]uint64, 30)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i)
+	}
+
+	values := make([]uint64, len(in))
+	copy(values, in)
+	encoded, err := simple8b.EncodeAll(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Prefix the encoded words with a one word header
+	src := append([]uint64{uint64(len(in))}, encoded...)
+
+	decoded := make([]uint64, 240)
+	n, err := simple8b.DecodeAllSkip(decoded, src, 1)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), n; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
 		}
-		i += 1
 	}
 
-	if exp, got := len(in), i; got != exp {
+	if _, err := simple8b.DecodeAllSkip(decoded, src, len(src)+1); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestDecodeAllReuse(t *testing.T) {
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 300)
+	}
+	for i := 100; i < 500; i++ {
+		in[i] = 1
+	}
+
+	values := make([]uint64, len(in))
+	copy(values, in)
+	encoded, err := simple8b.EncodeAll(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	buf := make([]uint64, len(encoded), len(in))
+	copy(buf, encoded)
+
+	n, err := simple8b.DecodeAllReuse(buf)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), n; got != exp {
 		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
 	}
 
-	got, err := simple8b.CountBytesBetween(encoded, 100000, 100001)
+	decoded := buf[:n]
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+}
+
+func TestDecodeAllReuse_TooSmall(t *testing.T) {
+	in := make([]uint64, 100)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i)
+	}
+
+	encoded, err := simple8b.EncodeAll(in)
 	if err != nil {
-		t.Fatalf("Unexpected error in Count: %v", err)
+		t.Fatalf("Unexpected error: %v", err)
 	}
-	if got != 1 {
-		t.Fatalf("Count mismatch: got %v, exp %v", got, 1)
+
+	// EncodeAll reuses the input slice so limit the capacity to the encoded words
+	buf := encoded[:len(encoded):len(encoded)]
+	if _, err := simple8b.DecodeAllReuse(buf); err == nil {
+		t.Fatalf("Expected error, got nil")
 	}
 }
 
@@ -301,6 +1766,44 @@ func BenchmarkEncoder(b *testing.B) {
 		b.SetBytes(int64(len(x)) * 8)
 	}
 }
+
+func BenchmarkEncoder_Write(b *testing.B) {
+	x := make([]uint64, 100000)
+	for i := 0; i < len(x); i++ {
+		x[i] = uint64(i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc := simple8b.NewEncoder()
+		for _, v := range x {
+			enc.Write(v)
+		}
+		enc.Bytes()
+		b.SetBytes(int64(len(x)) * 8)
+	}
+}
+
+func BenchmarkEncoder_Grow(b *testing.B) {
+	x := make([]uint64, 100000)
+	for i := 0; i < len(x); i++ {
+		x[i] = uint64(i)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		enc := simple8b.NewEncoder()
+		enc.Grow(len(x))
+		for _, v := range x {
+			enc.Write(v)
+		}
+		enc.Bytes()
+		b.SetBytes(int64(len(x)) * 8)
+	}
+}
+
 func BenchmarkDecode(b *testing.B) {
 	total := 0
 
@@ -321,6 +1824,24 @@ func BenchmarkDecode(b *testing.B) {
 	}
 }
 
+func BenchmarkDecode_SameSelector(b *testing.B) {
+	// Random 12 bit values all pack with the 5 values per word selector
+	x := make([]uint64, 10240)
+	for i := 0; i < len(x); i++ {
+		x[i] = uint64(i*2654435761) & 4095
+	}
+	y, _ := simple8b.EncodeAll(x)
+
+	decoded := make([]uint64, len(x))
+
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		_, _ = simple8b.DecodeAll(decoded, y)
+		b.SetBytes(int64(len(decoded) * 8))
+	}
+}
+
 func BenchmarkDecoder(b *testing.B) {
 	enc := simple8b.NewEncoder()
 	x := make([]uint64, 1024)
@@ -0,0 +1,69 @@
+package simple8b
+
+// sampleBlockSize is the number of contiguous values encoded per sample by
+// SampleSelectors.  Contiguous blocks keep the runs that drive selector choice.
+const sampleBlockSize = 1024
+
+// SelectorHistogram returns the number of words that would be encoded with each
+// selector when packing src.  Values too large to encode are not counted.
+func SelectorHistogram(src []uint64) [16]int {
+	var counts [16]int
+	countSelectors(src, len(src), &counts)
+	return counts
+}
+
+// SampleSelectors estimates SelectorHistogram from a deterministic sample of
+// src.  Evenly spaced blocks covering roughly sampleRate of the values are
+// encoded and the selector counts are scaled up to the full length of src.
+func SampleSelectors(src []uint64, sampleRate float64) [16]int {
+	if sampleRate >= 1 || len(src) <= sampleBlockSize {
+		return SelectorHistogram(src)
+	}
+
+	blocks := (len(src) + sampleBlockSize - 1) / sampleBlockSize
+	step := 1
+	if sampleRate > 0 {
+		step = int(1 / sampleRate)
+	}
+	if step > blocks {
+		step = blocks
+	}
+
+	var (
+		counts  [16]int
+		sampled int
+	)
+	for i := 0; i < len(src); i += step * sampleBlockSize {
+		// Encode from the rest of src so words at the end of the block are
+		// packed as they would be in the full encoding.
+		sampled += countSelectors(src[i:], sampleBlockSize, &counts)
+	}
+
+	if sampled == 0 {
+		return counts
+	}
+
+	scale := float64(len(src)) / float64(sampled)
+	for i := range counts {
+		counts[i] = int(float64(counts[i])*scale + 0.5)
+	}
+	return counts
+}
+
+// countSelectors adds the selectors used to pack src to counts, stopping once at
+// least limit values have been examined.  Values too large to encode are
+// skipped.  It returns the number of values examined.
+func countSelectors(src []uint64, limit int, counts *[16]int) int {
+	var packed int
+	for packed < limit && packed < len(src) {
+		v, n, err := Encode(src[packed:])
+		if err != nil {
+			// The value that cannot be packed is at the front of src
+			packed += 1
+			continue
+		}
+		counts[v>>60]++
+		packed += n
+	}
+	return packed
+}
@@ -0,0 +1,76 @@
+package simple8b_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestSelectorHistogram(t *testing.T) {
+	in := make([]uint64, 0, 100)
+	for i := 0; i < 60; i++ {
+		in = append(in, 1)
+	}
+	for i := 0; i < 15; i++ {
+		in = append(in, 15)
+	}
+	in = append(in, 1<<59)
+
+	counts := simple8b.SelectorHistogram(in)
+
+	var exp [16]int
+	exp[2] = 1
+	exp[5] = 1
+	exp[15] = 1
+	if counts != exp {
+		t.Fatalf("SelectorHistogram mismatch: exp %v, got %v", exp, counts)
+	}
+}
+
+func TestSelectorHistogram_TooLarge(t *testing.T) {
+	in := make([]uint64, 0, 100)
+	for i := 0; i < 60; i++ {
+		in = append(in, 1)
+	}
+	in = append(in, 1<<62)
+	for i := 0; i < 15; i++ {
+		in = append(in, 15)
+	}
+
+	counts := simple8b.SelectorHistogram(in)
+
+	var exp [16]int
+	exp[2] = 1
+	exp[5] = 1
+	if counts != exp {
+		t.Fatalf("SelectorHistogram mismatch: exp %v, got %v", exp, counts)
+	}
+}
+
+func TestSampleSelectors(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	in := make([]uint64, 1000000)
+	for i := 0; i < len(in); i++ {
+		switch rnd.Intn(10) {
+		case 0:
+			in[i] = uint64(rnd.Intn(1 << 20))
+		default:
+			in[i] = uint64(rnd.Intn(16))
+		}
+	}
+
+	full := simple8b.SelectorHistogram(in)
+	sampled := simple8b.SampleSelectors(in, 0.1)
+
+	for sel := 0; sel < 16; sel++ {
+		diff := sampled[sel] - full[sel]
+		if diff < 0 {
+			diff = -diff
+		}
+
+		if diff > full[sel]/10+10 {
+			t.Fatalf("Selector %d estimate out of tolerance: exp %v, got %v", sel, full[sel], sampled[sel])
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package simple8b
+
+import "fmt"
+
+// Rechunk decodes the byte slice and re-encodes its values into blocks of
+// valuesPerBlock values each.  The last block holds any remaining values and
+// may be shorter.  Concatenating the blocks reproduces the original values.
+func Rechunk(b []byte, valuesPerBlock int) ([][]byte, error) {
+	if valuesPerBlock <= 0 {
+		return nil, fmt.Errorf("invalid values per block: %v", valuesPerBlock)
+	}
+
+	var (
+		blocks [][]byte
+		werr   error
+	)
+	enc := NewEncoder()
+	err := EachWord(b, func(vals []uint64) {
+		for _, v := range vals {
+			if werr != nil {
+				return
+			}
+
+			if werr = enc.Write(v); werr != nil {
+				return
+			}
+
+			if enc.ValueCount() == valuesPerBlock {
+				var block []byte
+				if block, werr = enc.BytesCopy(); werr != nil {
+					return
+				}
+				blocks = append(blocks, block)
+				enc.Reset()
+			}
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if werr != nil {
+		return nil, werr
+	}
+
+	if enc.ValueCount() > 0 {
+		block, err := enc.BytesCopy()
+		if err != nil {
+			return nil, err
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks, nil
+}
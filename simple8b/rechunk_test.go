@@ -0,0 +1,60 @@
+package simple8b_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestRechunk(t *testing.T) {
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 300)
+	}
+
+	blocks, err := simple8b.Rechunk(encodeValues(t, in), 128)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := 8, len(blocks); got != exp {
+		t.Fatalf("Block count mismatch: exp %v, got %v", exp, got)
+	}
+
+	var out []uint64
+	for i, block := range blocks {
+		n, err := simple8b.CountBytes(block)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		exp := 128
+		if i == len(blocks)-1 {
+			exp = 1000 - 7*128
+		}
+		if n != exp {
+			t.Fatalf("Block[%d] len mismatch: exp %v, got %v", i, exp, n)
+		}
+
+		dec := simple8b.NewDecoder(block)
+		for dec.Next() {
+			out = append(out, dec.Read())
+		}
+	}
+
+	if exp, got := len(in), len(out); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if out[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], out[i])
+		}
+	}
+}
+
+func TestRechunk_InvalidBlockSize(t *testing.T) {
+	if _, err := simple8b.Rechunk(nil, 0); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,77 @@
+package simple8b
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// RepackFromWidth converts a buffer of values that are already bit-packed at a
+// fixed width into encoded simple8b words.  Values are read least significant
+// bit first, starting at the low bit of src[0]; any trailing bits too few to
+// form a whole value are ignored.  Full words use the densest selector that
+// holds bits bits per value, and any remaining values are encoded normally.
+func RepackFromWidth(src []byte, bits int) ([]byte, error) {
+	if bits < 1 || bits > 60 {
+		return nil, fmt.Errorf("invalid bit width: %v", bits)
+	}
+
+	sel := 2
+	for selector[sel].bit < bits {
+		sel++
+	}
+
+	var (
+		buf   [240]uint64
+		word  [8]byte
+		count = len(src) * 8 / bits
+		pos   = 0
+		dst   []byte
+	)
+
+	for count > 0 {
+		n := selector[sel].n
+		if n > count {
+			n = count
+		}
+
+		for i := 0; i < n; i++ {
+			buf[i] = readBits(src, pos, bits)
+			pos += bits
+		}
+		count -= n
+
+		vals := buf[:n]
+		if n == selector[sel].n {
+			binary.BigEndian.PutUint64(word[:], selector[sel].pack(vals))
+			dst = append(dst, word[:]...)
+			continue
+		}
+
+		// Not enough values left to fill a word with the matching selector
+		for len(vals) > 0 {
+			v, m, err := Encode(vals)
+			if err != nil {
+				return nil, err
+			}
+			binary.BigEndian.PutUint64(word[:], v)
+			dst = append(dst, word[:]...)
+			vals = vals[m:]
+		}
+	}
+	return dst, nil
+}
+
+// readBits returns the bits wide value starting at bit pos of src.
+func readBits(src []byte, pos, bits int) uint64 {
+	var v uint64
+	for i := 0; i < bits; {
+		b := uint64(src[(pos+i)/8]) >> uint((pos+i)%8)
+		take := 8 - (pos+i)%8
+		if take > bits-i {
+			take = bits - i
+		}
+		v |= (b & (1<<uint(take) - 1)) << uint(i)
+		i += take
+	}
+	return v
+}
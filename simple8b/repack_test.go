@@ -0,0 +1,53 @@
+package simple8b_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestRepackFromWidth(t *testing.T) {
+	// 40 four bit values, two per byte with the first in the low nibble
+	in := make([]uint64, 40)
+	src := make([]byte, len(in)/2)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 16)
+		src[i/2] |= byte(in[i]) << uint(4*(i%2))
+	}
+
+	b, err := simple8b.RepackFromWidth(src, 4)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// The first two full words use the 4 bit selector
+	for i := 0; i < 2; i++ {
+		if sel := binary.BigEndian.Uint64(b[i*8:]) >> 60; sel != 5 {
+			t.Fatalf("Selector[%d] mismatch: exp %v, got %v", i, 5, sel)
+		}
+	}
+
+	dec := simple8b.NewDecoder(b)
+	i := 0
+	for dec.Next() {
+		if i >= len(in) {
+			t.Fatalf("Decoded too many values: got %v, exp %v", i, len(in))
+		}
+
+		if dec.Read() != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dec.Read())
+		}
+		i += 1
+	}
+
+	if exp, got := len(in), i; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestRepackFromWidth_InvalidWidth(t *testing.T) {
+	if _, err := simple8b.RepackFromWidth([]byte{1}, 61); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,60 @@
+package simple8b
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// ReverseDecoder converts a compressed byte slice to a stream of unsigned 64bit
+// integers, starting from the last value and ending with the first.
+type ReverseDecoder struct {
+	bytes []byte
+	buf   [240]uint64
+	i     int
+	err   error
+}
+
+// NewReverseDecoder returns a ReverseDecoder from a byte slice.  If the slice
+// ends with bytes that do not form a whole word, no values are returned and Err
+// reports the trailing bytes.
+func NewReverseDecoder(b []byte) *ReverseDecoder {
+	d := &ReverseDecoder{
+		bytes: b,
+	}
+	if len(b)%8 != 0 {
+		d.err = fmt.Errorf("invalid slice len remaining: %v", len(b)%8)
+	}
+	return d
+}
+
+// Next returns true if there are remaining values to be read.  Successive
+// calls to Next move the current element pointer towards the first value.
+func (d *ReverseDecoder) Next() bool {
+	if d.err != nil {
+		return false
+	}
+	d.i -= 1
+
+	for d.i < 0 {
+		if len(d.bytes) < 8 {
+			return false
+		}
+
+		v := binary.BigEndian.Uint64(d.bytes[len(d.bytes)-8:])
+		d.bytes = d.bytes[:len(d.bytes)-8]
+		n, _ := Decode(&d.buf, v)
+		d.i = n - 1
+	}
+	return true
+}
+
+// Read returns the current value.  Successive calls to Read return the same
+// value.
+func (d *ReverseDecoder) Read() uint64 {
+	return d.buf[d.i]
+}
+
+// Err returns the error that stopped decoding, if any.
+func (d *ReverseDecoder) Err() error {
+	return d.err
+}
@@ -0,0 +1,75 @@
+package simple8b_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestReverseDecoder(t *testing.T) {
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 77)
+	}
+	for i := 200; i < 440; i++ {
+		in[i] = 1
+	}
+
+	values := make([]uint64, len(in))
+	copy(values, in)
+	encoded, err := simple8b.EncodeAll(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	exp := make([]uint64, len(in))
+	n, err := simple8b.DecodeAll(exp, encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	exp = exp[:n]
+	for i, j := 0, len(exp)-1; i < j; i, j = i+1, j-1 {
+		exp[i], exp[j] = exp[j], exp[i]
+	}
+
+	b := encodeValues(t, in)
+	dec := simple8b.NewReverseDecoder(b)
+	i := 0
+	for dec.Next() {
+		if i >= len(exp) {
+			t.Fatalf("Decoded too many values: got %v, exp %v", i, len(exp))
+		}
+
+		if dec.Read() != exp[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, exp[i], dec.Read())
+		}
+		i += 1
+	}
+
+	if got := i; got != len(exp) {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", len(exp), got)
+	}
+}
+
+func TestReverseDecoder_Empty(t *testing.T) {
+	dec := simple8b.NewReverseDecoder(nil)
+	if dec.Next() {
+		t.Fatalf("Expected Next to return false but it returned true")
+	}
+
+	if err := dec.Err(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestReverseDecoder_Trailing(t *testing.T) {
+	b := append(encodeValues(t, []uint64{1, 2, 3}), 0)
+	dec := simple8b.NewReverseDecoder(b)
+	if dec.Next() {
+		t.Fatalf("Expected Next to return false but it returned true")
+	}
+
+	if dec.Err() == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,126 @@
+package simple8b
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/jwilder/encoding/bitops"
+)
+
+// EncodeAllSigned zigzag encodes src, so values near zero stay small whatever
+// their sign, and packs the result.  Zigzag values wider than 60 bits have
+// their top 4 bits stored separately, so the full int64 range is supported.
+//
+// The output holds the number of values and the length of the packed words as
+// uvarints, the packed low 60 bits of each value, then the number of wide
+// values followed by the index gap and high bits of each as uvarints.
+func EncodeAllSigned(src []int64) ([]byte, error) {
+	enc := NewEncoder()
+
+	var (
+		wide []byte
+		nw   int
+		last int
+		tmp  [binary.MaxVarintLen64]byte
+	)
+	for i, v := range src {
+		z := bitops.ZigZagEncode64(v)
+		if z > MaxValue {
+			wide = append(wide, tmp[:binary.PutUvarint(tmp[:], uint64(i-last))]...)
+			wide = append(wide, tmp[:binary.PutUvarint(tmp[:], z>>60)]...)
+			last = i
+			nw += 1
+		}
+
+		if err := enc.Write(z & MaxValue); err != nil {
+			return nil, err
+		}
+	}
+
+	words, err := enc.Bytes()
+	if err != nil {
+		return nil, err
+	}
+
+	b := make([]byte, 0, 3*binary.MaxVarintLen64+len(words)+len(wide))
+	b = append(b, tmp[:binary.PutUvarint(tmp[:], uint64(len(src)))]...)
+	b = append(b, tmp[:binary.PutUvarint(tmp[:], uint64(len(words)))]...)
+	b = append(b, words...)
+	b = append(b, tmp[:binary.PutUvarint(tmp[:], uint64(nw))]...)
+	return append(b, wide...), nil
+}
+
+// DecodeAllSigned writes the values encoded by EncodeAllSigned to dst and
+// returns the number of values written.
+func DecodeAllSigned(dst []int64, b []byte) (int, error) {
+	count, b, err := readUvarint(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if count > uint64(len(dst)) {
+		return 0, fmt.Errorf("dst too small: need at least %v", count)
+	}
+
+	size, b, err := readUvarint(b)
+	if err != nil {
+		return 0, err
+	}
+
+	if size > uint64(len(b)) || size%8 != 0 {
+		return 0, fmt.Errorf("invalid packed length: %v", size)
+	}
+	words, b := b[:size], b[size:]
+
+	z := make([]uint64, count)
+	j := 0
+	err = EachWord(words, func(vals []uint64) {
+		if j < len(z) {
+			copy(z[j:], vals)
+		}
+		j += len(vals)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	if uint64(j) != count {
+		return 0, fmt.Errorf("value count mismatch: exp %v, got %v", count, j)
+	}
+
+	nw, b, err := readUvarint(b)
+	if err != nil {
+		return 0, err
+	}
+
+	idx := uint64(0)
+	for k := uint64(0); k < nw; k++ {
+		var gap, high uint64
+		if gap, b, err = readUvarint(b); err != nil {
+			return 0, err
+		}
+		if high, b, err = readUvarint(b); err != nil {
+			return 0, err
+		}
+
+		idx += gap
+		if idx >= count || high > 0xF {
+			return 0, fmt.Errorf("invalid wide value at index %v", idx)
+		}
+		z[idx] |= high << 60
+	}
+
+	for i, v := range z {
+		dst[i] = bitops.ZigZagDecode64(v)
+	}
+	return int(count), nil
+}
+
+// readUvarint reads a uvarint from the front of b and returns the rest of b.
+func readUvarint(b []byte) (uint64, []byte, error) {
+	v, n := binary.Uvarint(b)
+	if n <= 0 {
+		return 0, nil, fmt.Errorf("invalid uvarint")
+	}
+	return v, b[n:], nil
+}
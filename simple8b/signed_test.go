@@ -0,0 +1,62 @@
+package simple8b_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestEncodeAllSigned(t *testing.T) {
+	in := []int64{
+		0, 1, -1, 2, -2,
+		math.MaxInt64, math.MinInt64, math.MaxInt64 - 1, math.MinInt64 + 1,
+		1 << 59, -(1 << 59), 1<<59 - 1, -(1<<59 - 1), 1 << 60, -(1 << 60),
+		1 << 62, -(1 << 62), 7, -7,
+	}
+	for i := 0; i < 500; i++ {
+		in = append(in, int64(i%11)-5)
+	}
+	in = append(in, math.MinInt64)
+
+	b, err := simple8b.EncodeAllSigned(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	decoded := make([]int64, len(in))
+	n, err := simple8b.DecodeAllSigned(decoded, b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), n; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+
+	if _, err := simple8b.DecodeAllSigned(decoded[:len(in)-1], b); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestEncodeAllSigned_Empty(t *testing.T) {
+	b, err := simple8b.EncodeAllSigned(nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	n, err := simple8b.DecodeAllSigned(nil, b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if n != 0 {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", 0, n)
+	}
+}
@@ -0,0 +1,48 @@
+package simple8b
+
+import "fmt"
+
+// EncodeSortedUnique encodes a strictly increasing slice of values.  Since
+// consecutive distinct values differ by at least 1, each value after the first
+// is stored as the gap from the previous value minus 1, which produces smaller
+// values than plain delta encoding for dense sets.  An error is returned if src
+// is not strictly increasing.
+func EncodeSortedUnique(src []uint64) ([]byte, error) {
+	enc := NewEncoder()
+	for i, v := range src {
+		if i == 0 {
+			if err := enc.Write(v); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if v <= src[i-1] {
+			return nil, fmt.Errorf("values not strictly increasing at index %v", i)
+		}
+
+		if err := enc.Write(v - src[i-1] - 1); err != nil {
+			return nil, err
+		}
+	}
+	return enc.Bytes()
+}
+
+// DecodeSortedUnique returns the values encoded by EncodeSortedUnique.
+func DecodeSortedUnique(b []byte) ([]uint64, error) {
+	n, err := CountBytes(b)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make([]uint64, 0, n)
+	dec := NewDecoder(b)
+	for dec.Next() {
+		v := dec.Read()
+		if len(values) > 0 {
+			v += values[len(values)-1] + 1
+		}
+		values = append(values, v)
+	}
+	return values, nil
+}
@@ -0,0 +1,72 @@
+package simple8b_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestEncodeSortedUnique_Dense(t *testing.T) {
+	in := make([]uint64, 1000)
+	in[0] = 5000
+	for i := 1; i < len(in); i++ {
+		in[i] = in[i-1] + 1 + uint64(i%2)
+	}
+
+	b, err := simple8b.EncodeSortedUnique(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testSortedUnique(t, b, in)
+
+	// Compare against plain delta encoding of the same set
+	enc := simple8b.NewEncoder()
+	enc.Write(in[0])
+	for i := 1; i < len(in); i++ {
+		enc.Write(in[i] - in[i-1])
+	}
+	delta, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(b) >= len(delta) {
+		t.Fatalf("Expected smaller encoding than delta: got %v, delta %v", len(b), len(delta))
+	}
+}
+
+func TestEncodeSortedUnique_Sparse(t *testing.T) {
+	in := make([]uint64, 1000)
+	for i := 1; i < len(in); i++ {
+		in[i] = in[i-1] + 1 + uint64(i*i%100000)
+	}
+
+	b, err := simple8b.EncodeSortedUnique(in)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	testSortedUnique(t, b, in)
+}
+
+func TestEncodeSortedUnique_NotSorted(t *testing.T) {
+	if _, err := simple8b.EncodeSortedUnique([]uint64{1, 2, 2, 3}); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func testSortedUnique(t *testing.T, b []byte, in []uint64) {
+	decoded, err := simple8b.DecodeSortedUnique(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), len(decoded); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if decoded[i] != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], decoded[i])
+		}
+	}
+}
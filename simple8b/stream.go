@@ -0,0 +1,38 @@
+package simple8b
+
+// Stream decodes the byte slice in a separate goroutine and sends each value on
+// the returned value channel, which is buffered with bufSize elements.  Both
+// channels are closed once decoding completes.  If the byte slice is invalid,
+// the error is sent on the error channel after all preceding values.  Callers
+// must read the value channel until it is closed; use StreamDone to stop early.
+func Stream(b []byte, bufSize int) (<-chan uint64, <-chan error) {
+	return StreamDone(b, bufSize, nil)
+}
+
+// StreamDone is like Stream, but closing done stops decoding early and closes
+// both channels without an error.  A nil done is never closed.
+func StreamDone(b []byte, bufSize int, done <-chan struct{}) (<-chan uint64, <-chan error) {
+	values := make(chan uint64, bufSize)
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(values)
+
+		err := walkWords(b, func(vals []uint64) bool {
+			for _, val := range vals {
+				select {
+				case values <- val:
+				case <-done:
+					return false
+				}
+			}
+			return true
+		})
+		if err != nil {
+			errs <- err
+		}
+	}()
+
+	return values, errs
+}
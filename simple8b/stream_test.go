@@ -0,0 +1,92 @@
+package simple8b_test
+
+import (
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestStream(t *testing.T) {
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 50)
+	}
+
+	values := make([]uint64, len(in))
+	copy(values, in)
+	encoded, err := simple8b.EncodeAll(values)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	exp := make([]uint64, len(in))
+	n, err := simple8b.DecodeAll(exp, encoded)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	enc := simple8b.NewEncoder()
+	for _, v := range in {
+		enc.Write(v)
+	}
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	ch, errs := simple8b.Stream(b, 16)
+	var got []uint64
+	for v := range ch {
+		got = append(got, v)
+	}
+
+	if err := <-errs; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if len(got) != n {
+		t.Fatalf("Stream len mismatch: exp %v, got %v", n, len(got))
+	}
+
+	for i := 0; i < n; i++ {
+		if got[i] != exp[i] {
+			t.Fatalf("Streamed[%d] != %v, got %v", i, exp[i], got[i])
+		}
+	}
+}
+
+func TestStream_Invalid(t *testing.T) {
+	ch, errs := simple8b.Stream([]byte{0, 1, 2}, 0)
+	for range ch {
+		t.Fatalf("Expected no values")
+	}
+
+	if err := <-errs; err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
+
+func TestStreamDone(t *testing.T) {
+	in := make([]uint64, 10000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 50)
+	}
+
+	done := make(chan struct{})
+	ch, errs := simple8b.StreamDone(encodeValues(t, in), 0, done)
+
+	// Stop reading after a few values
+	for i := 0; i < 10; i++ {
+		if v := <-ch; v != in[i] {
+			t.Fatalf("Streamed[%d] != %v, got %v", i, in[i], v)
+		}
+	}
+	close(done)
+
+	// The producer exits and closes both channels
+	for range ch {
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
@@ -0,0 +1,54 @@
+package simple8b
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+)
+
+// TranscodeRaw reads a stream of raw little-endian uint64 values from r and
+// writes them to w as compressed simple8b words.  Encoded words are written as
+// they are produced so the whole input never needs to be held in memory.  It
+// returns the number of values read and the number of bytes written.
+func TranscodeRaw(r io.Reader, w io.Writer) (valuesIn int, bytesOut int, err error) {
+	var raw [8]byte
+
+	br := bufio.NewReader(r)
+	bw := bufio.NewWriter(w)
+	enc := NewEncoder()
+
+	for {
+		if _, err := io.ReadFull(br, raw[:]); err == io.EOF {
+			break
+		} else if err != nil {
+			return valuesIn, bytesOut, err
+		}
+
+		if err := enc.Write(binary.LittleEndian.Uint64(raw[:])); err != nil {
+			return valuesIn, bytesOut, err
+		}
+		valuesIn++
+
+		// Drain any words the encoder has flushed so far
+		if _, b := enc.drain(); len(b) > 0 {
+			n, err := bw.Write(b)
+			bytesOut += n
+			if err != nil {
+				return valuesIn, bytesOut, err
+			}
+		}
+	}
+
+	b, err := enc.Bytes()
+	if err != nil {
+		return valuesIn, bytesOut, err
+	}
+
+	n, err := bw.Write(b)
+	bytesOut += n
+	if err != nil {
+		return valuesIn, bytesOut, err
+	}
+
+	return valuesIn, bytesOut, bw.Flush()
+}
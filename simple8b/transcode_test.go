@@ -0,0 +1,62 @@
+package simple8b_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestTranscodeRaw(t *testing.T) {
+	in := make([]uint64, 5000)
+	for i := 0; i < len(in); i++ {
+		in[i] = uint64(i % 1000)
+	}
+
+	var raw bytes.Buffer
+	for _, v := range in {
+		binary.Write(&raw, binary.LittleEndian, v)
+	}
+
+	var out bytes.Buffer
+	valuesIn, bytesOut, err := simple8b.TranscodeRaw(&raw, &out)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), valuesIn; got != exp {
+		t.Fatalf("Values in mismatch: exp %v, got %v", exp, got)
+	}
+
+	if exp, got := out.Len(), bytesOut; got != exp {
+		t.Fatalf("Bytes out mismatch: exp %v, got %v", exp, got)
+	}
+
+	dec := simple8b.NewDecoder(out.Bytes())
+	i := 0
+	for dec.Next() {
+		if i >= len(in) {
+			t.Fatalf("Decoded too many values: got %v, exp %v", i, len(in))
+		}
+
+		if dec.Read() != in[i] {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, in[i], dec.Read())
+		}
+		i += 1
+	}
+
+	if exp, got := len(in), i; got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestTranscodeRaw_PartialValue(t *testing.T) {
+	raw := bytes.NewReader([]byte{1, 0, 0, 0, 0, 0, 0, 0, 2, 0})
+
+	var out bytes.Buffer
+	if _, _, err := simple8b.TranscodeRaw(raw, &out); err != io.ErrUnexpectedEOF {
+		t.Fatalf("Error mismatch: exp %v, got %v", io.ErrUnexpectedEOF, err)
+	}
+}
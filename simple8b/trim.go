@@ -0,0 +1,102 @@
+package simple8b
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/jwilder/encoding/bitops"
+)
+
+// TrimZeros returns the number of leading and trailing zeros in src along with
+// the values between them.  Storing the run lengths separately and encoding only
+// the trimmed values avoids spending words on long zero margins.  If src is all
+// zeros, lead is len(src) and trimmed is empty.  trimmed shares storage with src.
+func TrimZeros(src []uint64) (lead int, trimmed []uint64, trail int) {
+	for lead < len(src) && src[lead] == 0 {
+		lead++
+	}
+
+	end := len(src)
+	for end > lead && src[end-1] == 0 {
+		end--
+	}
+
+	return lead, src[lead:end], len(src) - end
+}
+
+// PadZeros reverses TrimZeros, returning a new slice of trimmed surrounded by
+// lead leading and trail trailing zeros.
+func PadZeros(lead int, trimmed []uint64, trail int) []uint64 {
+	dst := make([]uint64, lead+len(trimmed)+trail)
+	copy(dst[lead:], trimmed)
+	return dst
+}
+
+// StripConstant subtracts the smallest value of src from every value, returning
+// that offset and a new slice of the reduced values.  Columns sharing a large
+// common base, such as timestamps within the same minute, need far fewer bits
+// per value once the base is removed.  An empty src returns a zero offset.
+func StripConstant(src []uint64) (offset uint64, stripped []uint64) {
+	if len(src) == 0 {
+		return 0, nil
+	}
+
+	offset, _, _, _ = bitops.MinMax(src)
+
+	stripped = make([]uint64, len(src))
+	for i, v := range src {
+		stripped[i] = v - offset
+	}
+	return offset, stripped
+}
+
+// RestoreConstant reverses StripConstant, returning a new slice of stripped
+// with offset added back to every value.
+func RestoreConstant(offset uint64, stripped []uint64) []uint64 {
+	dst := make([]uint64, len(stripped))
+	for i, v := range stripped {
+		dst[i] = v + offset
+	}
+	return dst
+}
+
+// EncodeAllZeros returns a compact descriptor holding only the length of src as
+// a uvarint if every value in src is zero.  Zeros cannot use the run selectors,
+// which only encode ones, so packing them costs a word per 60 values.  If src
+// is empty, longer than maxZeroRun or holds a non-zero value, it returns false
+// and no bytes so the caller can fall back to another encoding.
+func EncodeAllZeros(src []uint64) ([]byte, bool) {
+	if len(src) == 0 || len(src) > maxZeroRun {
+		return nil, false
+	}
+
+	for _, v := range src {
+		if v != 0 {
+			return nil, false
+		}
+	}
+
+	b := make([]byte, binary.MaxVarintLen64)
+	return b[:binary.PutUvarint(b, uint64(len(src)))], true
+}
+
+// maxZeroRun is the longest run of zeros EncodeAllZeros describes.
+const maxZeroRun = 1 << 28
+
+// DecodeAllZeros returns the zero values described by a descriptor produced by
+// EncodeAllZeros.  The descriptor must not be followed by other bytes.
+func DecodeAllZeros(b []byte) ([]uint64, error) {
+	count, n := binary.Uvarint(b)
+	if n <= 0 {
+		return nil, fmt.Errorf("unable to decode count")
+	}
+
+	if n != len(b) {
+		return nil, fmt.Errorf("invalid slice len remaining: %v", len(b)-n)
+	}
+
+	if count > maxZeroRun {
+		return nil, fmt.Errorf("count too large: %v > %v", count, maxZeroRun)
+	}
+	return make([]uint64, count), nil
+}
@@ -0,0 +1,144 @@
+package simple8b_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestTrimZeros(t *testing.T) {
+	in := make([]uint64, 10000)
+	for i := 4000; i < 4100; i++ {
+		in[i] = uint64(i)
+	}
+
+	lead, trimmed, trail := simple8b.TrimZeros(in)
+	if exp, got := 4000, lead; got != exp {
+		t.Fatalf("Lead mismatch: exp %v, got %v", exp, got)
+	}
+	if exp, got := 100, len(trimmed); got != exp {
+		t.Fatalf("Trimmed len mismatch: exp %v, got %v", exp, got)
+	}
+	if exp, got := 5900, trail; got != exp {
+		t.Fatalf("Trail mismatch: exp %v, got %v", exp, got)
+	}
+
+	full := encodeValues(t, in)
+	var hdr [2 * binary.MaxVarintLen64]byte
+	n := binary.PutUvarint(hdr[:], uint64(lead))
+	n += binary.PutUvarint(hdr[n:], uint64(trail))
+	size := n + len(encodeValues(t, trimmed))
+
+	if size >= len(full) {
+		t.Fatalf("Expected trimmed encoding to be smaller: got %v, full %v", size, len(full))
+	}
+
+	padded := simple8b.PadZeros(lead, trimmed, trail)
+	if exp, got := len(in), len(padded); got != exp {
+		t.Fatalf("Padded len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if padded[i] != in[i] {
+			t.Fatalf("Padded[%d] != %v, got %v", i, in[i], padded[i])
+		}
+	}
+}
+
+func TestTrimZeros_AllZeros(t *testing.T) {
+	lead, trimmed, trail := simple8b.TrimZeros(make([]uint64, 50))
+	if lead != 50 || len(trimmed) != 0 || trail != 0 {
+		t.Fatalf("TrimZeros mismatch: got %v, %v, %v", lead, len(trimmed), trail)
+	}
+}
+
+func TestStripConstant(t *testing.T) {
+	in := make([]uint64, 1000)
+	for i := 0; i < len(in); i++ {
+		in[i] = 1<<50 + uint64((i*7919)%60000)
+	}
+
+	offset, stripped := simple8b.StripConstant(in)
+	if exp := uint64(1 << 50); offset != exp {
+		t.Fatalf("Offset mismatch: exp %v, got %v", exp, offset)
+	}
+
+	if full, got := len(encodeValues(t, in)), len(encodeValues(t, stripped))+8; got >= full {
+		t.Fatalf("Expected stripped encoding to be smaller: got %v, full %v", got, full)
+	}
+
+	restored := simple8b.RestoreConstant(offset, stripped)
+	if exp, got := len(in), len(restored); got != exp {
+		t.Fatalf("Restored len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i := 0; i < len(in); i++ {
+		if restored[i] != in[i] {
+			t.Fatalf("Restored[%d] != %v, got %v", i, in[i], restored[i])
+		}
+	}
+}
+
+func TestEncodeAllZeros(t *testing.T) {
+	in := make([]uint64, 10000)
+
+	b, ok := simple8b.EncodeAllZeros(in)
+	if !ok {
+		t.Fatalf("Expected all zeros to be detected")
+	}
+
+	if exp, got := 2, len(b); got != exp {
+		t.Fatalf("Encoded len mismatch: exp %v, got %v", exp, got)
+	}
+
+	if packed := len(encodeValues(t, in)); len(b) >= packed {
+		t.Fatalf("Expected smaller encoding than packed: got %v, packed %v", len(b), packed)
+	}
+
+	out, err := simple8b.DecodeAllZeros(b)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp, got := len(in), len(out); got != exp {
+		t.Fatalf("Decode len mismatch: exp %v, got %v", exp, got)
+	}
+
+	for i, v := range out {
+		if v != 0 {
+			t.Fatalf("Decoded[%d] != %v, got %v", i, 0, v)
+		}
+	}
+
+	in[9999] = 1
+	if _, ok := simple8b.EncodeAllZeros(in); ok {
+		t.Fatalf("Expected non-zero value to be detected")
+	}
+}
+
+func TestDecodeAllZeros_Invalid(t *testing.T) {
+	var b [binary.MaxVarintLen64 + 1]byte
+
+	// Counts larger than EncodeAllZeros writes
+	n := binary.PutUvarint(b[:], math.MaxUint64)
+	if _, err := simple8b.DecodeAllZeros(b[:n]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	n = binary.PutUvarint(b[:], 1<<28+1)
+	if _, err := simple8b.DecodeAllZeros(b[:n]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	// Trailing bytes after the count
+	n = binary.PutUvarint(b[:], 10)
+	if _, err := simple8b.DecodeAllZeros(b[:n+1]); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	if _, err := simple8b.DecodeAllZeros(nil); err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}
@@ -0,0 +1,35 @@
+package simple8b
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+)
+
+// Validate reads encoded words from r and checks that each one is well formed.
+// It returns the number of bytes that precede the first invalid word, or the
+// total bytes read if every word is valid.  A word is invalid if it has data in
+// bits its selector leaves unused (see Decoder.SetLenientMode).  An error is
+// returned for an invalid word, a trailing partial word or a read failure.
+func Validate(r io.Reader) (goodBytes int64, err error) {
+	var word [8]byte
+
+	br := bufio.NewReader(r)
+	for {
+		n, err := io.ReadFull(br, word[:])
+		if err == io.EOF {
+			return goodBytes, nil
+		} else if err == io.ErrUnexpectedEOF {
+			return goodBytes, fmt.Errorf("misaligned word at offset %v: %v trailing bytes", goodBytes, n)
+		} else if err != nil {
+			return goodBytes, err
+		}
+
+		v := binary.BigEndian.Uint64(word[:])
+		if hasUnknownMarker(v) {
+			return goodBytes, fmt.Errorf("invalid word at offset %v: selector %v", goodBytes, v>>60)
+		}
+		goodBytes += 8
+	}
+}
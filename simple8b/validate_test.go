@@ -0,0 +1,68 @@
+package simple8b_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestValidate(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 1000; i++ {
+		enc.Write(uint64(i))
+	}
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	good, err := simple8b.Validate(bytes.NewReader(b))
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if exp := int64(len(b)); good != exp {
+		t.Fatalf("Good bytes mismatch: exp %v, got %v", exp, good)
+	}
+}
+
+func TestValidate_Corrupt(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	for i := 0; i < 1000; i++ {
+		enc.Write(uint64(i))
+	}
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// Corrupt the fourth word with a run selector carrying payload bits
+	corrupt := append([]byte(nil), b...)
+	binary.BigEndian.PutUint64(corrupt[24:], 1<<60|42)
+
+	good, err := simple8b.Validate(bytes.NewReader(corrupt))
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	if exp := int64(24); good != exp {
+		t.Fatalf("Good bytes mismatch: exp %v, got %v", exp, good)
+	}
+}
+
+func TestValidate_Misaligned(t *testing.T) {
+	b := make([]byte, 20)
+	binary.BigEndian.PutUint64(b, 15<<60|5)
+	binary.BigEndian.PutUint64(b[8:], 15<<60|6)
+
+	good, err := simple8b.Validate(bytes.NewReader(b))
+	if err == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+
+	if exp := int64(16); good != exp {
+		t.Fatalf("Good bytes mismatch: exp %v, got %v", exp, good)
+	}
+}
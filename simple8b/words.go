@@ -0,0 +1,50 @@
+package simple8b
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// WordIterator steps through the words of a compressed byte slice without
+// unpacking their values.  It exposes the raw word, its selector and the number
+// of values it holds for tools that inspect the encoded format.
+type WordIterator struct {
+	bytes []byte
+	word  uint64
+	err   error
+}
+
+// NewWordIterator returns a WordIterator from a byte slice.
+func NewWordIterator(b []byte) *WordIterator {
+	return &WordIterator{
+		bytes: b,
+	}
+}
+
+// Next returns true if there is another word to be read.  If the slice ends
+// with bytes that do not form a whole word, Next returns false once it reaches
+// them and Err reports the trailing bytes.
+func (w *WordIterator) Next() bool {
+	if len(w.bytes) < 8 {
+		if len(w.bytes) > 0 && w.err == nil {
+			w.err = fmt.Errorf("invalid slice len remaining: %v", len(w.bytes))
+		}
+		return false
+	}
+
+	w.word = binary.BigEndian.Uint64(w.bytes[:8])
+	w.bytes = w.bytes[8:]
+	return true
+}
+
+// Word returns the current raw word, its selector and the number of values it
+// holds.  Successive calls to Word return the same word.
+func (w *WordIterator) Word() (rawWord uint64, sel int, count int) {
+	sel = int(w.word >> 60)
+	return w.word, sel, selector[sel].n
+}
+
+// Err returns the error that stopped iteration, if any.
+func (w *WordIterator) Err() error {
+	return w.err
+}
@@ -0,0 +1,74 @@
+package simple8b_test
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/jwilder/encoding/simple8b"
+)
+
+func TestWordIterator(t *testing.T) {
+	enc := simple8b.NewEncoder()
+	// 240 ones (selector 0), 60 one bit values (selector 2), 5 12 bit values
+	// (selector 11), 1 large (selector 15)
+	for i := 0; i < 240; i++ {
+		enc.Write(1)
+	}
+	for i := 0; i < 60; i++ {
+		enc.Write(0)
+	}
+	for i := 0; i < 5; i++ {
+		enc.Write(4000)
+	}
+	enc.Write(1 << 50)
+
+	b, err := enc.Bytes()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expSelectors := []int{0, 2, 11, 15}
+	expCounts := []int{240, 60, 5, 1}
+
+	it := simple8b.NewWordIterator(b)
+	i := 0
+	for it.Next() {
+		if i >= len(expSelectors) {
+			t.Fatalf("Word count mismatch: exp %v, got more", len(expSelectors))
+		}
+
+		raw, sel, count := it.Word()
+		if exp := binary.BigEndian.Uint64(b[i*8:]); raw != exp {
+			t.Fatalf("Word[%d] != %v, got %v", i, exp, raw)
+		}
+		if sel != expSelectors[i] {
+			t.Fatalf("Selector[%d] mismatch: exp %v, got %v", i, expSelectors[i], sel)
+		}
+		if count != expCounts[i] {
+			t.Fatalf("Count[%d] mismatch: exp %v, got %v", i, expCounts[i], count)
+		}
+		i += 1
+	}
+
+	if exp, got := len(expSelectors), i; got != exp {
+		t.Fatalf("Word count mismatch: exp %v, got %v", exp, got)
+	}
+}
+
+func TestWordIterator_Trailing(t *testing.T) {
+	b := append(encodeValues(t, []uint64{1, 2, 3}), 0, 0)
+
+	it := simple8b.NewWordIterator(b)
+	words := 0
+	for it.Next() {
+		words++
+	}
+
+	if exp, got := 1, words; got != exp {
+		t.Fatalf("Word count mismatch: exp %v, got %v", exp, got)
+	}
+
+	if it.Err() == nil {
+		t.Fatalf("Expected error, got nil")
+	}
+}